@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// calendar is a set of dates keyed "2006-01-02", such as a list of company holidays.
+type calendar map[string]bool
+
+func (c calendar) contains(date time.Time) bool {
+	return c[date.Format("2006-01-02")]
+}
+
+// calendarFlag collects named calendars from repeated -calendar [name=]path flags.
+type calendarFlag map[string]calendar
+
+func (f calendarFlag) String() string {
+	names := make([]string, 0, len(f))
+	for name := range f {
+		names = append(names, name)
+	}
+	return strings.Join(names, ",")
+}
+
+func (f calendarFlag) Set(value string) error {
+	name, path, ok := strings.Cut(value, "=")
+	if !ok {
+		path = value
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	cal, err := loadCalendar(path)
+	if err != nil {
+		return err
+	}
+	f[strings.ToLower(name)] = cal
+	return nil
+}
+
+// loadCalendar reads a calendar file, either an iCalendar (.ics) file or a
+// plain list with one YYYY-MM-DD date per line.
+func loadCalendar(path string) (calendar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cal calendar
+	if strings.EqualFold(filepath.Ext(path), ".ics") || strings.HasPrefix(strings.TrimSpace(string(data)), "BEGIN:VCALENDAR") {
+		cal, err = readICS(strings.NewReader(string(data)), time.Now().AddDate(icsRecurrenceYears, 0, 0))
+	} else {
+		cal, err = readDateList(strings.NewReader(string(data)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cal, nil
+}
+
+// readDateList reads one date per line. Anything after the date is a label and
+// is ignored, as are blank lines and lines starting with #.
+func readDateList(r io.Reader) (calendar, error) {
+	cal := calendar{}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		d, err := time.Parse("2006-01-02", strings.Fields(line)[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		cal[d.Format("2006-01-02")] = true
+	}
+	return cal, scanner.Err()
+}
+
+// icsRecurrenceYears is how many years from now recurring events without an
+// end are expanded for.
+const icsRecurrenceYears = 10
+
+// icsEvent is what readICS needs of a VEVENT.
+type icsEvent struct {
+	uid          string
+	start, end   time.Time
+	allDay       bool
+	rule         string      // RRULE value, "" if it does not recur
+	rdates       []time.Time // extra occurrences
+	exdates      []time.Time // occurrences that are left out
+	recurrenceID time.Time   // the occurrence of uid this event replaces
+}
+
+// readICS collects the days covered by each VEVENT in an iCalendar stream.
+// All-day events cover DTSTART up to, but not including, DTEND; timed events
+// cover the day they start on. Recurring events cover each of their
+// occurrences, up to through for rules without COUNT or UNTIL.
+func readICS(r io.Reader, through time.Time) (calendar, error) {
+	var events []*icsEvent
+	var event *icsEvent
+	for _, line := range unfoldICS(r) {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, _, _ = strings.Cut(name, ";")
+		name = strings.ToUpper(name)
+		if name == "BEGIN" && strings.EqualFold(value, "VEVENT") {
+			event = &icsEvent{}
+			continue
+		}
+		if event == nil {
+			continue
+		}
+		switch name {
+		case "UID":
+			event.uid = value
+		case "DTSTART":
+			d, err := parseICSDate(value)
+			if err != nil {
+				return nil, err
+			}
+			event.start = d
+			event.allDay = len(value) == len("20060102")
+		case "DTEND":
+			d, err := parseICSDate(value)
+			if err != nil {
+				return nil, err
+			}
+			event.end = d
+		case "RRULE":
+			event.rule = value
+		case "RDATE", "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				d, err := parseICSDate(v)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+				if name == "RDATE" {
+					event.rdates = append(event.rdates, d)
+				} else {
+					event.exdates = append(event.exdates, d)
+				}
+			}
+		case "RECURRENCE-ID":
+			d, err := parseICSDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("RECURRENCE-ID: %w", err)
+			}
+			event.recurrenceID = d
+		case "END":
+			if !strings.EqualFold(value, "VEVENT") {
+				continue
+			}
+			if event.start.IsZero() {
+				return nil, fmt.Errorf("VEVENT without DTSTART")
+			}
+			events = append(events, event)
+			event = nil
+		}
+	}
+
+	// Occurrences moved by a RECURRENCE-ID event are gone from their series.
+	replaced := map[string][]time.Time{}
+	for _, e := range events {
+		if !e.recurrenceID.IsZero() {
+			replaced[e.uid] = append(replaced[e.uid], e.recurrenceID)
+		}
+	}
+	cal := calendar{}
+	for _, e := range events {
+		starts := []time.Time{e.start}
+		if e.rule != "" && e.recurrenceID.IsZero() {
+			rule, err := parseRRule("DTSTART:" + e.start.Format("20060102") + " RRULE:" + e.rule)
+			if err != nil {
+				return nil, fmt.Errorf("RRULE of the VEVENT on %s: %w", e.start.Format("2006-01-02"), err)
+			}
+			starts = append(starts, rule.occurrences(through)...)
+		}
+		starts = append(starts, e.rdates...)
+		for _, start := range starts {
+			if slices.ContainsFunc(e.exdates, start.Equal) || e.recurrenceID.IsZero() && slices.ContainsFunc(replaced[e.uid], start.Equal) {
+				continue
+			}
+			cal[start.Format("2006-01-02")] = true
+			if e.allDay && !e.end.IsZero() {
+				for i := 1; i < daysBetween(e.start, e.end); i++ {
+					cal[start.AddDate(0, 0, i).Format("2006-01-02")] = true
+				}
+			}
+		}
+	}
+	return cal, nil
+}
+
+// unfoldICS splits an iCalendar stream into logical lines, joining continuation
+// lines that start with a space or tab.
+func unfoldICS(r io.Reader) []string {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// parseICSDate parses the date part of an iCalendar DATE or DATE-TIME value.
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid iCalendar date %q", value)
+	}
+	d, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid iCalendar date %q", value)
+	}
+	return d, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestReadDateList(t *testing.T) {
+	cal, err := readDateList(strings.NewReader("# Company holidays\n2026-12-25 Christmas\n\n2026-12-26\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, calendar{"2026-12-25": true, "2026-12-26": true}, cal)
+}
+
+func TestReadDateList_Invalid(t *testing.T) {
+	_, err := readDateList(strings.NewReader("2026-12-25\nChristmas\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestReadICS(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20261224",
+		"DTEND;VALUE=DATE:20261227",
+		"SUMMARY:Winter",
+		"  break",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20260501T090000Z",
+		"DTEND:20260501T170000Z",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	cal, err := readICS(strings.NewReader(ics), time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, calendar{
+		"2026-12-24": true,
+		"2026-12-25": true,
+		"2026-12-26": true,
+		"2026-05-01": true,
+	}, cal)
+}
+
+func TestReadICS_Recurring(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:christmas",
+		"DTSTART;VALUE=DATE:20241225",
+		"DTEND;VALUE=DATE:20241227",
+		"RRULE:FREQ=YEARLY",
+		"EXDATE;VALUE=DATE:20251225",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:offsite",
+		"DTSTART:20260105T090000Z",
+		"RRULE:FREQ=MONTHLY;BYDAY=1MO;COUNT=3",
+		"RDATE;VALUE=DATE:20260601,20260701",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:offsite",
+		"RECURRENCE-ID:20260202T090000Z",
+		"DTSTART:20260203T090000Z",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	cal, err := readICS(strings.NewReader(ics), time.Date(2026, time.December, 31, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, calendar{
+		"2024-12-25": true, "2024-12-26": true,
+		"2026-12-25": true, "2026-12-26": true,
+		"2026-01-05": true, "2026-02-03": true, "2026-03-02": true,
+		"2026-06-01": true, "2026-07-01": true,
+	}, cal)
+
+	_, err = readICS(strings.NewReader("BEGIN:VEVENT\nDTSTART:20260105\nRRULE:FREQ=HOURLY\nEND:VEVENT\n"), time.Now())
+	assert.EqualError(t, err, `RRULE of the VEVENT on 2026-01-05: unsupported FREQ "HOURLY"`)
+}
+
+func TestCalendarFlag(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Company.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("2026-12-25\n"), 0o644))
+
+	f := calendarFlag{}
+	assert.NoError(t, f.Set(path))
+	assert.NoError(t, f.Set("uk="+path))
+	assert.True(t, f["company"]["2026-12-25"])
+	assert.True(t, f["uk"]["2026-12-25"])
+	assert.Error(t, f.Set(filepath.Join(dir, "missing.txt")))
+}
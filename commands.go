@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// command is a linear-future subcommand.
+type command struct {
+	name    string
+	args    string // synopsis of the arguments, e.g. "<team name>..."
+	summary string
+	minArgs int
+	maxArgs int  // -1 for no limit
+	offline bool // runs without LINEAR_API_KEY and without a config
+	// setupStatus is the exit status when the command cannot start, such as
+	// on an invalid command line; 2 if zero.
+	setupStatus int
+	flags       func(o *options, fs *flag.FlagSet)
+	run         func(ctx context.Context, token string, cfg config, args []string) int
+}
+
+// commands returns all commands, in the order help lists them.
+func commands() []command {
+	return []command{
+		{
+			name:    "run",
+			args:    "<team name>...",
+			summary: "Create issues from the teams' templates that are due today.",
+			minArgs: 1,
+			maxArgs: -1,
+			flags: func(o *options, fs *flag.FlagSet) {
+				o.addScheduleFlags(fs)
+				o.addRunFlags(fs)
+				o.addAPIFlags(fs)
+			},
+			run: runTeams,
+		},
+		{
+			name:    "list",
+			summary: "Show template schedules, upcoming dates and sub-issue problems.",
+			flags: func(o *options, fs *flag.FlagSet) {
+				o.addScheduleFlags(fs)
+				o.addFormatFlag(fs, "text", "json", "yaml")
+				o.addAPIFlags(fs)
+			},
+			run: func(ctx context.Context, token string, cfg config, _ []string) int {
+				return runList(ctx, token, cfg)
+			},
+		},
+		{
+			name:    "templates",
+			summary: "List all templates with their descriptions.",
+			flags: func(o *options, fs *flag.FlagSet) {
+				o.addFormatFlag(fs, "text", "json", "yaml")
+				o.addAPIFlags(fs)
+			},
+			run: func(ctx context.Context, token string, cfg config, _ []string) int {
+				return runListTemplates(ctx, token, cfg)
+			},
+		},
+		{
+			name:        "lint",
+			summary:     "Check all templates for problems; exit 2 on errors, 1 on warnings with -fail-on warning, 3 if they could not be checked.",
+			setupStatus: 3,
+			flags: func(o *options, fs *flag.FlagSet) {
+				o.addScheduleFlags(fs)
+				o.addFormatFlag(fs, "text", "json", "yaml", "sarif")
+				o.addFailOnFlag(fs)
+				o.addAPIFlags(fs)
+			},
+			run: func(ctx context.Context, token string, cfg config, _ []string) int {
+				return runLint(ctx, token, cfg)
+			},
+		},
+		{
+			name:    "forecast",
+			args:    "[team name...]",
+			summary: "Show the issues that will be created over the next days, as an agenda or calendar.",
+			maxArgs: -1,
+			flags: func(o *options, fs *flag.FlagSet) {
+				o.addScheduleFlags(fs)
+				o.addDaysFlag(fs)
+				o.addFormatFlag(fs, "text", "json", "yaml", "ics")
+				o.addAPIFlags(fs)
+			},
+			run: runForecast,
+		},
+		{
+			name:    "explain",
+			args:    "<template> [YYYY-MM-DD]",
+			summary: "Explain why an issue is or is not created from a template today, or on a date.",
+			minArgs: 1,
+			maxArgs: 2,
+			flags: func(o *options, fs *flag.FlagSet) {
+				o.addCalendarFlags(fs)
+				o.addCatchUpFlags(fs)
+				o.addAPIFlags(fs)
+			},
+			run: func(ctx context.Context, token string, cfg config, args []string) int {
+				if len(args) > 1 {
+					date, err := time.Parse("2006-01-02", args[1])
+					if err != nil {
+						fmt.Fprintf(os.Stderr, "invalid date: %v\n", err)
+						return 2
+					}
+					// Today's time of day matters, other days are taken whole.
+					if !date.Equal(civilDate(time.Now().In(cfg.location))) {
+						cfg.clock = dateClock(date)
+					}
+				}
+				return runExplain(ctx, token, cfg, args[0])
+			},
+		},
+		{
+			name:    "repair",
+			args:    "<issue>",
+			summary: "Set up the sub-issue dependencies of an issue again, adding what is missing.",
+			minArgs: 1,
+			maxArgs: 1,
+			flags: func(o *options, fs *flag.FlagSet) {
+				o.addDryRunFlag(fs)
+				o.addAPIFlags(fs)
+			},
+			run: func(ctx context.Context, token string, cfg config, args []string) int {
+				return runRepair(ctx, token, cfg, args[0])
+			},
+		},
+		{
+			name:    "completion",
+			args:    "bash|zsh|fish",
+			summary: "Print a shell completion script.",
+			minArgs: 1,
+			maxArgs: 1,
+			offline: true,
+			run: func(_ context.Context, _ string, _ config, args []string) int {
+				return runCompletion(os.Stdout, args[0])
+			},
+		},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// flagSet returns the command's flags, stored in o, with help text.
+func (c command) flagSet(o *options) *flag.FlagSet {
+	fs := flag.NewFlagSet("linear-future "+c.name, flag.ContinueOnError)
+	if c.flags != nil {
+		c.flags(o, fs)
+	}
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: linear-future %s\n\n%s\n", c.synopsis(), c.summary)
+		hasFlags := false
+		fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintf(fs.Output(), "\nFlags:\n")
+			fs.PrintDefaults()
+		}
+	}
+	return fs
+}
+
+// failedSetup returns the exit status for when the command cannot start.
+func (c command) failedSetup() int {
+	if c.setupStatus != 0 {
+		return c.setupStatus
+	}
+	return 2
+}
+
+func (c command) synopsis() string {
+	if c.args == "" {
+		return c.name + " [flags]"
+	}
+	return c.name + " [flags] " + c.args
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: LINEAR_API_KEY=lin_api_... linear-future <command> [flags] [arguments]\n\nCommands:\n")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-40s %s\n", cmd.synopsis(), cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun \"linear-future help <command>\" for a command's flags.\n")
+}
+
+func runCommandLine(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return 2
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		if len(args) > 1 {
+			if cmd, ok := findCommand(args[1]); ok {
+				fs := cmd.flagSet(newOptions())
+				fs.SetOutput(os.Stdout)
+				fs.Usage()
+				return 0
+			}
+		}
+		printUsage(os.Stdout)
+		return 0
+	}
+
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		return runLegacyCommandLine(args)
+	}
+	o := newOptions()
+	fs := cmd.flagSet(o)
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return cmd.failedSetup()
+	}
+	return execute(cmd, o, fs.Args())
+}
+
+// runLegacyCommandLine handles the command line from before there were
+// commands: flags, then -list, -list-templates or team names. It is
+// deprecated. Teams named like a command can only be run with the run command
+// when they come first, as the command wins.
+func runLegacyCommandLine(args []string) int {
+	o := newOptions()
+	fs := flag.NewFlagSet("linear-future", flag.ContinueOnError)
+	list := fs.Bool("list", false, "Deprecated: use the list command")
+	listTemplates := fs.Bool("list-templates", false, "Deprecated: use the templates command")
+	o.addScheduleFlags(fs)
+	o.addRunFlags(fs)
+	o.addFormatFlag(fs, "text", "json", "yaml")
+	o.addAPIFlags(fs)
+	fs.Usage = func() { printUsage(fs.Output()) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	name, rest := "run", fs.Args()
+	switch {
+	case *listTemplates:
+		name, rest = "templates", nil
+	case *list:
+		name, rest = "list", nil
+	}
+	cmd, _ := findCommand(name)
+	fmt.Fprintf(os.Stderr, "warning: this command line is deprecated, use \"linear-future %s\" instead\n", cmd.synopsis())
+	return execute(cmd, o, rest)
+}
+
+// execute runs cmd with the parsed flags in o. SIGINT and SIGTERM, or running
+// out of -timeout, cancel the context it runs with.
+func execute(cmd command, o *options, args []string) int {
+	if len(args) < cmd.minArgs || cmd.maxArgs >= 0 && len(args) > cmd.maxArgs {
+		fmt.Fprintf(os.Stderr, "Usage: linear-future %s\n", cmd.synopsis())
+		return cmd.failedSetup()
+	}
+	if cmd.offline {
+		return cmd.run(context.Background(), "", config{}, args)
+	}
+
+	token := os.Getenv("LINEAR_API_KEY")
+	if token == "" {
+		fmt.Fprintln(os.Stderr, "LINEAR_API_KEY is not set")
+		return cmd.failedSetup()
+	}
+	cfg, err := o.config()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return cmd.failedSetup()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if cfg.runTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.runTimeout)
+		defer cancel()
+	}
+	// Once stopping, a second signal kills the process as usual.
+	context.AfterFunc(ctx, stop)
+
+	return cmd.run(ctx, token, cfg, args)
+}
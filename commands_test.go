@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestRunCommandLine_Usage(t *testing.T) {
+	t.Setenv("LINEAR_API_KEY", "")
+	assert.Equal(t, 2, runCommandLine(nil))
+	assert.Equal(t, 0, runCommandLine([]string{"help", "lint"}))
+	assert.Equal(t, 2, runCommandLine([]string{"run"}), "run needs a team")
+	assert.Equal(t, 2, runCommandLine([]string{"repair", "ENG-1", "ENG-2"}))
+	assert.Equal(t, 2, runCommandLine([]string{"list", "-fail-on", "none"}), "only lint has -fail-on")
+	assert.Equal(t, 3, runCommandLine([]string{"lint", "-fail-on", "sometimes"}), "lint could not check")
+	assert.Equal(t, 3, runCommandLine([]string{"lint", "extra"}))
+	assert.Equal(t, 2, runCommandLine([]string{"list"}), "LINEAR_API_KEY is not set")
+	assert.Equal(t, 3, runCommandLine([]string{"lint"}), "LINEAR_API_KEY is not set")
+	t.Setenv("LINEAR_API_KEY", "lin_api_test")
+	assert.Equal(t, 3, runCommandLine([]string{"lint", "-tz", "Nowhere/Special"}))
+}
+
+func TestRunCommandLine_Commands(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	t.Setenv("LINEAR_API_KEY", q.token)
+	fake.addTemplate("team-1", "Weekly", "Recurrence: Mon")
+
+	assert.Equal(t, 0, runCommandLine([]string{"list", "-endpoint", q.endpoint, "-format", "json"}))
+	assert.Equal(t, 0, runCommandLine([]string{"templates", "-endpoint", q.endpoint}))
+	assert.Equal(t, 0, runCommandLine([]string{"lint", "-endpoint", q.endpoint, "-format", "sarif"}))
+	assert.Equal(t, 0, runCommandLine([]string{"forecast", "-endpoint", q.endpoint, "-days", "90", "-format", "ics", "Test Team"}))
+	assert.Equal(t, 2, runCommandLine([]string{"forecast", "-endpoint", q.endpoint, "-days", "0"}))
+	assert.Equal(t, 0, runCommandLine([]string{"run", "-endpoint", q.endpoint, "-date", "2026-03-02", "Test Team"}))
+	assert.Equal(t, 1, len(fake.issuesFromTemplate(fake.templates[0].id)))
+}
+
+func TestRunCommandLine_Legacy(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	t.Setenv("LINEAR_API_KEY", q.token)
+	fake.addTemplate("team-1", "Weekly", "Recurrence: Mon")
+
+	assert.Equal(t, 0, runCommandLine([]string{"-list", "-endpoint", q.endpoint}))
+	assert.Equal(t, 0, runCommandLine([]string{"-endpoint", q.endpoint, "-list-templates", "-format", "yaml"}))
+	assert.Equal(t, 2, runCommandLine([]string{"-endpoint", q.endpoint, "-format", "sarif", "-list"}))
+	assert.Equal(t, 2, runCommandLine([]string{"-endpoint", q.endpoint, "-fail-on", "none", "Test Team"}), "only lint has -fail-on")
+
+	// After flags, lint and repair are team names, as they were before there were commands.
+	fake.teams = append(fake.teams, &fakeTeam{id: "team-lint", name: "lint"})
+	lint := fake.addTemplate("team-lint", "Lint", "Recurrence: Mon")
+	assert.Equal(t, 0, runCommandLine([]string{"-endpoint", q.endpoint, "-date", "2026-03-02", "lint"}))
+	assert.Equal(t, 1, len(fake.issuesFromTemplate(lint)))
+	assert.Equal(t, 0, runCommandLine([]string{"-endpoint", q.endpoint, "-date", "2026-03-02", "Test Team"}))
+	assert.Equal(t, 1, len(fake.issuesFromTemplate(fake.templates[0].id)))
+}
+
+func TestChoiceFlag(t *testing.T) {
+	f := choiceFlag{value: "text", choices: []string{"text", "json"}}
+	assert.NoError(t, f.Set("json"))
+	assert.Equal(t, "json", f.String())
+	assert.EqualError(t, f.Set("xml"), "must be one of text, json")
+	assert.Equal(t, "json", f.String())
+}
+
+func TestRunCompletion(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		t.Run(shell, func(t *testing.T) {
+			var b strings.Builder
+			assert.Equal(t, 0, runCompletion(&b, shell))
+			script := b.String()
+			for _, cmd := range commands() {
+				assert.Contains(t, script, cmd.name)
+			}
+			for _, flag := range []string{"fail-on", "dry-run", "calendar", "max-catch-up"} {
+				assert.Contains(t, script, flag)
+			}
+		})
+	}
+	assert.Equal(t, 2, runCompletion(&strings.Builder{}, "tcsh"))
+}
+
+func TestCompletionFlags(t *testing.T) {
+	cmd, ok := findCommand("run")
+	assert.True(t, ok)
+	flags := map[string]completionFlag{}
+	for _, f := range completionFlags(cmd) {
+		flags[f.name] = f
+	}
+	assert.True(t, flags["dry-run"].isBool)
+	assert.True(t, flags["state"].isFile)
+	assert.True(t, flags["calendar"].isFile)
+	assert.False(t, flags["tz"].isBool || flags["tz"].isFile)
+	_, hasFormat := flags["format"]
+	assert.False(t, hasFormat)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// completionFlag is a command flag as the completion scripts see it.
+type completionFlag struct {
+	name   string
+	usage  string
+	isBool bool // takes no value
+	isFile bool // takes a file name
+}
+
+// completionFlags returns the flags of cmd, sorted by name.
+func completionFlags(cmd command) []completionFlag {
+	var flags []completionFlag
+	cmd.flagSet(newOptions()).VisitAll(func(f *flag.Flag) {
+		valueName, usage := flag.UnquoteUsage(f)
+		bf, isBool := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{
+			name:   f.Name,
+			usage:  usage,
+			isBool: isBool && bf.IsBoolFlag(),
+			isFile: strings.Contains(valueName, "file"),
+		})
+	})
+	return flags
+}
+
+// runCompletion prints the completion script for shell.
+func runCompletion(w io.Writer, shell string) int {
+	switch shell {
+	case "bash":
+		writeBashCompletion(w)
+	case "zsh":
+		writeZshCompletion(w)
+	case "fish":
+		writeFishCompletion(w)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown shell %q, expected bash, zsh or fish\n", shell)
+		return 2
+	}
+	return 0
+}
+
+func writeBashCompletion(w io.Writer) {
+	var names []string
+	for _, cmd := range commands() {
+		names = append(names, cmd.name)
+	}
+
+	fmt.Fprintf(w, "# bash completion for linear-future. Load with: source <(linear-future completion bash)\n")
+	fmt.Fprintf(w, "_linear_future() {\n")
+	fmt.Fprintf(w, "\tlocal cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]}\n")
+	fmt.Fprintf(w, "\tif [[ $COMP_CWORD -eq 1 ]]; then\n")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(append(names, "help"), " "))
+	fmt.Fprintf(w, "\t\treturn\n")
+	fmt.Fprintf(w, "\tfi\n")
+	fmt.Fprintf(w, "\tcase ${COMP_WORDS[1]} in\n")
+	fmt.Fprintf(w, "\thelp)\n\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n\t\t;;\n", strings.Join(names, " "))
+	for _, cmd := range commands() {
+		var all, valued []string
+		for _, f := range completionFlags(cmd) {
+			all = append(all, "-"+f.name)
+			if !f.isBool {
+				valued = append(valued, "-"+f.name)
+			}
+		}
+		fmt.Fprintf(w, "\t%s)\n", cmd.name)
+		if len(valued) > 0 {
+			// Let -o default complete file names for flag values.
+			fmt.Fprintf(w, "\t\tcase $prev in %s) return ;; esac\n", strings.Join(valued, "|"))
+		}
+		if cmd.name == "completion" {
+			fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W \"bash zsh fish\" -- \"$cur\"))\n")
+		} else if len(all) > 0 {
+			fmt.Fprintf(w, "\t\t[[ $cur == -* ]] && COMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(all, " "))
+		}
+		fmt.Fprintf(w, "\t\t;;\n")
+	}
+	fmt.Fprintf(w, "\tesac\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "complete -o default -F _linear_future linear-future\n")
+}
+
+func writeZshCompletion(w io.Writer) {
+	fmt.Fprintf(w, "#compdef linear-future\n")
+	fmt.Fprintf(w, "# zsh completion for linear-future. Load with: source <(linear-future completion zsh)\n")
+	fmt.Fprintf(w, "_linear_future() {\n")
+	fmt.Fprintf(w, "\tlocal -a commands\n")
+	fmt.Fprintf(w, "\tcommands=(\n")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "\t\t%s\n", shellQuote(cmd.name+":"+cmd.summary))
+	}
+	fmt.Fprintf(w, "\t)\n")
+	fmt.Fprintf(w, "\tif (( CURRENT == 2 )); then\n")
+	fmt.Fprintf(w, "\t\t_describe command commands\n")
+	fmt.Fprintf(w, "\t\treturn\n")
+	fmt.Fprintf(w, "\tfi\n")
+	fmt.Fprintf(w, "\tlocal cmd=$words[2]\n")
+	fmt.Fprintf(w, "\tshift words\n")
+	fmt.Fprintf(w, "\t(( CURRENT-- ))\n")
+	fmt.Fprintf(w, "\tcase $cmd in\n")
+	fmt.Fprintf(w, "\thelp)\n\t\t_describe command commands\n\t\t;;\n")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "\t%s)\n", cmd.name)
+		fmt.Fprintf(w, "\t\t_arguments")
+		for _, f := range completionFlags(cmd) {
+			spec := "-" + f.name + "[" + zshEscape(f.usage) + "]"
+			switch {
+			case f.isFile:
+				spec += ":file:_files"
+			case !f.isBool:
+				spec += ":value: "
+			}
+			fmt.Fprintf(w, " \\\n\t\t\t%s", shellQuote(spec))
+		}
+		switch {
+		case cmd.name == "completion":
+			fmt.Fprintf(w, " \\\n\t\t\t'1:shell:(bash zsh fish)'")
+		case cmd.args != "":
+			fmt.Fprintf(w, " \\\n\t\t\t'*:%s: '", zshEscape(cmd.args))
+		}
+		fmt.Fprintf(w, "\n\t\t;;\n")
+	}
+	fmt.Fprintf(w, "\tesac\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "compdef _linear_future linear-future\n")
+}
+
+// zshEscape escapes the characters that are special in _arguments and
+// _describe specs.
+func zshEscape(s string) string {
+	return strings.NewReplacer(`[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(s)
+}
+
+// shellQuote single-quotes s for a POSIX shell, zsh or fish.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func writeFishCompletion(w io.Writer) {
+	fmt.Fprintf(w, "# fish completion for linear-future. Load with: linear-future completion fish | source\n")
+	fmt.Fprintf(w, "complete -c linear-future -f\n")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "complete -c linear-future -n __fish_use_subcommand -a %s -d %s\n", cmd.name, shellQuote(cmd.summary))
+	}
+	fmt.Fprintf(w, "complete -c linear-future -n __fish_use_subcommand -a help -d 'Show help for a command.'\n")
+	for _, cmd := range commands() {
+		cond := shellQuote("__fish_seen_subcommand_from " + cmd.name)
+		for _, f := range completionFlags(cmd) {
+			fmt.Fprintf(w, "complete -c linear-future -n %s -o %s -d %s", cond, f.name, shellQuote(f.usage))
+			switch {
+			case f.isFile:
+				fmt.Fprintf(w, " -r -F")
+			case !f.isBool:
+				fmt.Fprintf(w, " -r")
+			}
+			fmt.Fprintf(w, "\n")
+		}
+		if cmd.name == "completion" {
+			fmt.Fprintf(w, "complete -c linear-future -n %s -a 'bash zsh fish'\n", cond)
+		}
+	}
+}
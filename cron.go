@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+}
+
+var cronDowNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+var cronMonthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+// cronSpec is a parsed five-field cron expression. Schedules work with whole
+// days, so only the day-of-month, month and day-of-week fields decide whether
+// a date matches; minute and hour are validated and kept.
+type cronSpec struct {
+	expr    string
+	minutes uint64 // bit n set if minute n matches
+	hours   uint64
+	doms    uint64
+	months  uint64
+	dows    uint64
+	domStar bool // day-of-month field starts with *
+	dowStar bool // day-of-week field starts with *
+}
+
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: cronMonthNames},
+	{name: "day of week", min: 0, max: 7, names: cronDowNames},
+}
+
+// parseCron parses "minute hour day-of-month month day-of-week" or one of the
+// @yearly, @monthly, @weekly and @daily macros.
+func parseCron(expr string) (*cronSpec, error) {
+	expr = strings.TrimSpace(expr)
+	fields := strings.Fields(expr)
+	if len(fields) == 1 {
+		macro, ok := cronMacros[strings.ToLower(fields[0])]
+		if !ok {
+			return nil, fmt.Errorf("unknown cron macro %q", fields[0])
+		}
+		fields = strings.Fields(macro)
+	}
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression needs %d fields, got %d", len(cronFields), len(fields))
+	}
+
+	var sets [5]uint64
+	for i, f := range cronFields {
+		set, err := parseCronField(fields[i], f)
+		if err != nil {
+			return nil, fmt.Errorf("%s field %q: %w", f.name, fields[i], err)
+		}
+		sets[i] = set
+	}
+	// Both 0 and 7 mean Sunday.
+	if sets[4]&(1<<7) != 0 {
+		sets[4] = sets[4]&^(1<<7) | 1
+	}
+	return &cronSpec{
+		expr:    expr,
+		minutes: sets[0],
+		hours:   sets[1],
+		doms:    sets[2],
+		months:  sets[3],
+		dows:    sets[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField parses a comma-separated list of *, N, N-M, with an optional /step.
+func parseCronField(field string, f cronField) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+
+		lo, hi := f.min, f.max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = parseCronValue(loStr, f); err != nil {
+				return 0, err
+			}
+			switch {
+			case isRange:
+				if hi, err = parseCronValue(hiStr, f); err != nil {
+					return 0, err
+				}
+				if hi < lo {
+					return 0, fmt.Errorf("range %q is backwards", rng)
+				}
+			case !hasStep:
+				hi = lo
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func parseCronValue(s string, f cronField) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// matches reports whether the date fields match date. As in Vixie cron, when both
+// day-of-month and day-of-week are restricted, a date matching either one matches.
+func (c *cronSpec) matches(date time.Time) bool {
+	if c.months&(1<<date.Month()) == 0 {
+		return false
+	}
+	domMatch := c.doms&(1<<date.Day()) != 0
+	dowMatch := c.dows&(1<<date.Weekday()) != 0
+	if !c.domStar && !c.dowStar {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// timeOfDay returns the time after midnight the expression fires at, if it fires at exactly one time of day.
+func (c *cronSpec) timeOfDay() (time.Duration, bool) {
+	if bits.OnesCount64(c.hours) != 1 || bits.OnesCount64(c.minutes) != 1 {
+		return 0, false
+	}
+	return time.Duration(bits.TrailingZeros64(c.hours))*time.Hour + time.Duration(bits.TrailingZeros64(c.minutes))*time.Minute, true
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestParseCron_Fields(t *testing.T) {
+	c, err := parseCron("*/15 9-17 1,15 jan-mar mon-fri")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1|1<<15|1<<30|1<<45), c.minutes)
+	assert.Equal(t, uint64(0x3fe00), c.hours)
+	assert.Equal(t, uint64(1<<1|1<<15), c.doms)
+	assert.Equal(t, uint64(1<<1|1<<2|1<<3), c.months)
+	assert.Equal(t, uint64(0x3e), c.dows)
+	assert.False(t, c.domStar)
+	assert.False(t, c.dowStar)
+}
+
+func TestParseCron_SundaySeven(t *testing.T) {
+	c, err := parseCron("0 0 * * 5-7")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1|1<<5|1<<6), c.dows)
+}
+
+func TestParseCron_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"0 0 * *",
+		"0 0 * * * *",
+		"60 0 * * *",
+		"0 24 * * *",
+		"0 0 0 * *",
+		"0 0 * 13 *",
+		"0 0 * * 8",
+		"0 0 5-1 * *",
+		"0 0 */0 * *",
+		"0 0 * * funday",
+		"@hourly",
+	} {
+		_, err := parseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestCronMatches(t *testing.T) {
+	// Every other day of the month, on weekdays only.
+	desc := "Recurrence: cron 0 0 */2 * 1-5"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.March, 3)))  // Tue, day 3
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.March, 2))) // Mon, day 2
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.March, 7))) // Sat, day 7
+}
+
+func TestCronMatches_DomOrDow(t *testing.T) {
+	desc := "Recurrence: cron 0 0 13 * fri"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.March, 13))) // Fri 13th
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.April, 13))) // Mon 13th
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.March, 6)))  // Fri
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.March, 9)))
+}
+
+func TestCronMatches_Macros(t *testing.T) {
+	assert.True(t, templateMatchesSchedule("Recurrence: cron @monthly", date(2026, time.May, 1)))
+	assert.False(t, templateMatchesSchedule("Recurrence: cron @monthly", date(2026, time.May, 2)))
+	assert.True(t, templateMatchesSchedule("Recurrence: cron @weekly", date(2026, time.May, 3)))
+	assert.True(t, templateMatchesSchedule("Recurrence: cron @yearly", date(2026, time.January, 1)))
+	assert.False(t, templateMatchesSchedule("Recurrence: cron @yearly", date(2026, time.February, 1)))
+}
+
+func TestCronMalformedIsReported(t *testing.T) {
+	s := parseRecurrence("cron 0 0 32 * *", "Recurrence: cron 0 0 32 * *")
+	assert.Equal(t, scheduleMalformed, s.kind)
+	assert.Equal(t, "**MALFORMED**: Recurrence: cron 0 0 32 * * (day of month field \"32\": value 32 out of range 1-31)", formatSchedule(s))
+}
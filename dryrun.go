@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// dryRun stands in for Linear's mutations during -dry-run. Reads still go to
+// the API; writes are printed as a plan instead. Issues that would be created
+// get placeholder IDs, and their sub-issues are predicted from the template.
+type dryRun struct {
+	templates map[string]issueTemplate // template ID -> template, as read by getTemplates
+	children  map[string][]subIssue    // placeholder issue ID -> predicted sub-issues
+	issues    int
+	out       io.Writer // where the plan is printed
+}
+
+func newDryRun() *dryRun {
+	return &dryRun{
+		templates: map[string]issueTemplate{},
+		children:  map[string][]subIssue{},
+		out:       os.Stdout,
+	}
+}
+
+func (d *dryRun) recordTemplates(templates []issueTemplate) {
+	for _, t := range templates {
+		d.templates[t.id] = t
+	}
+}
+
+func (d *dryRun) createIssueFromTemplate(templateID, teamID string, createdAt time.Time) string {
+	d.issues++
+	id := fmt.Sprintf("dry-run-issue-%d", d.issues)
+	tmpl := d.templates[templateID]
+	fmt.Fprintf(d.out, "  [dry run] would create issue %s %q from template %q in team %s", id, tmpl.issueTitle, tmpl.name, teamID)
+	if !createdAt.IsZero() {
+		fmt.Fprintf(d.out, ", dated %s", createdAt.Format(time.RFC3339))
+	}
+	fmt.Fprintln(d.out)
+
+	var children []subIssue
+	for i, title := range tmpl.subIssueTitles {
+		child := subIssue{id: fmt.Sprintf("%s-sub-%d", id, i+1), title: title}
+		fmt.Fprintf(d.out, "  [dry run]   with sub-issue %s %q\n", child.id, child.title)
+		children = append(children, child)
+	}
+	d.children[id] = children
+	return id
+}
+
+// planned reports whether issueID is an issue the dry run pretended to create.
+// It is safe to call on a nil *dryRun.
+func (d *dryRun) planned(issueID string) bool {
+	if d == nil {
+		return false
+	}
+	_, ok := d.children[issueID]
+	return ok
+}
+
+// plannedChildren returns the predicted sub-issues of an issue the dry run
+// pretended to create. It is safe to call on a nil *dryRun.
+func (d *dryRun) plannedChildren(issueID string) ([]subIssue, bool) {
+	if d == nil {
+		return nil, false
+	}
+	children, ok := d.children[issueID]
+	return children, ok
+}
+
+func (d *dryRun) createBlocksRelation(blockerID, blockedID string) {
+	fmt.Fprintf(d.out, "  [dry run] would make %s block %s\n", blockerID, blockedID)
+}
+
+func (d *dryRun) updateTitle(issueID, newTitle string) {
+	fmt.Fprintf(d.out, "  [dry run] would rename %s to %q\n", issueID, newTitle)
+}
+
+func (d *dryRun) deleteIssue(issueID string) {
+	fmt.Fprintf(d.out, "  [dry run] would delete %s\n", issueID)
+}
+
+func (d *dryRun) createComment(issueID, body string) {
+	fmt.Fprintf(d.out, "  [dry run] would comment on %s:\n", issueID)
+	for _, line := range strings.Split(body, "\n") {
+		fmt.Fprintf(d.out, "  [dry run]   %s\n", line)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestDryRun_PlansWithoutAPI(t *testing.T) {
+	// No token and no server: any real request would fail.
+	d := newDryRun()
+	var out strings.Builder
+	d.out = &out
+	d.recordTemplates([]issueTemplate{{
+		id:             "tmpl-1",
+		name:           "Release",
+		issueTitle:     "Release checklist",
+		subIssueTitles: []string{"1|REQ Build", "2|DEPS1 Ship", "Announce"},
+	}})
+	q := q{dryRun: d}
+
+	issueID, err := createIssueFromTemplate(context.Background(), q, "tmpl-1", "team-1", time.Time{})
+	assert.NoError(t, err)
+	assert.Equal(t, "dry-run-issue-1", issueID)
+
+	children, err := getChildIssues(context.Background(), q, issueID)
+	assert.NoError(t, err)
+	assert.Equal(t, []subIssue{
+		{id: "dry-run-issue-1-sub-1", title: "1|REQ Build"},
+		{id: "dry-run-issue-1-sub-2", title: "2|DEPS1 Ship"},
+		{id: "dry-run-issue-1-sub-3", title: "Announce"},
+	}, children)
+
+	assert.NoError(t, setupSubIssueDependencies(context.Background(), q, issueID))
+	plan := out.String()
+	assert.Contains(t, plan, `  [dry run] would create issue dry-run-issue-1 "Release checklist" from template "Release" in team team-1
+  [dry run]   with sub-issue dry-run-issue-1-sub-1 "1|REQ Build"
+  [dry run]   with sub-issue dry-run-issue-1-sub-2 "2|DEPS1 Ship"
+  [dry run]   with sub-issue dry-run-issue-1-sub-3 "Announce"
+`)
+	assert.Contains(t, plan, "  [dry run] would make dry-run-issue-1-sub-1 block dry-run-issue-1\n")
+	assert.Contains(t, plan, "  [dry run] would make dry-run-issue-1-sub-1 block dry-run-issue-1-sub-2\n")
+	assert.Contains(t, plan, "  [dry run] would rename dry-run-issue-1-sub-1 to \"Build\"\n")
+	assert.Contains(t, plan, "  [dry run] would rename dry-run-issue-1-sub-2 to \"Ship\"\n")
+	assert.NotContains(t, plan, "would rename dry-run-issue-1-sub-3")
+	assert.Contains(t, plan, "  [dry run] would comment on dry-run-issue-1:\n")
+
+	out.Reset()
+	_, err = createIssueFromTemplate(context.Background(), q, "tmpl-1", "team-1", time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), `would create issue dry-run-issue-2 "Release checklist" from template "Release" in team team-1, dated 2026-03-02T09:00:00Z`+"\n")
+}
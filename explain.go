@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// runExplain prints why a run would or would not create an issue from a
+// template today, by cfg's clock.
+func runExplain(ctx context.Context, token string, cfg config, templateRef string) int {
+	if err := explain(ctx, cfg.newQ(token), cfg, os.Stdout, templateRef); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to explain template %q: %v\n", templateRef, err)
+		return 1
+	}
+	return 0
+}
+
+// explain goes through the checks createFromDueTemplates makes for the
+// template with the ID or name templateRef, and writes the outcome of each.
+// With cfg.statePath, it takes catch-up into account like a run does.
+func explain(ctx context.Context, q q, cfg config, w io.Writer, templateRef string) error {
+	templates, err := getTemplates(ctx, q)
+	if err != nil {
+		return err
+	}
+	tmpl, err := lookUpTemplate(templates, templateRef)
+	if err != nil {
+		return err
+	}
+	teams, err := getTeams(ctx, q)
+	if err != nil {
+		return err
+	}
+	teamName := ""
+	for _, t := range teams {
+		if t.id == tmpl.teamID {
+			teamName = t.name
+		}
+	}
+
+	ts := parseTemplateSchedule(tmpl.description, cfg.calendars)
+	loc := ts.locationOr(cfg.location)
+	now := cfg.now(loc)
+	date := ts.today(now, cfg.location)
+	day := date.Format("2006-01-02")
+	var reasons []string // why no issue would be created
+
+	fmt.Fprintf(w, "Template %q (%s) on %s, %s, in %s\n", tmpl.name, tmpl.id, date.Weekday(), day, loc)
+
+	fmt.Fprintln(w, "Schedule:")
+	scheduled := false
+	for _, s := range ts.schedules {
+		if s.kind == scheduleMalformed {
+			continue
+		}
+		if s.matches(date) {
+			scheduled = true
+			fmt.Fprintf(w, "  %s: matches\n", formatSchedule(s))
+		} else {
+			fmt.Fprintf(w, "  %s: does not match\n", formatSchedule(s))
+		}
+	}
+	if !scheduled {
+		fmt.Fprintln(w, "  no line matches")
+	}
+	for _, s := range ts.schedules {
+		if s.kind == scheduleMalformed {
+			fmt.Fprintf(w, "  ignored %s\n", formatSchedule(s))
+		}
+	}
+
+	if ts.adjust != adjustNone {
+		switch adjusted := ts.adjusted(date); {
+		case scheduled && !adjusted:
+			fmt.Fprintf(w, "%s: %s is not a business day, so it moves away\n", formatAdjustment(ts), day)
+		case !scheduled && adjusted:
+			fmt.Fprintf(w, "%s: a trigger moves to %s\n", formatAdjustment(ts), day)
+		default:
+			fmt.Fprintf(w, "%s: nothing moves to or from %s\n", formatAdjustment(ts), day)
+		}
+	}
+	if !ts.adjusted(date) {
+		reasons = append(reasons, "the schedule does not put it on "+day)
+	}
+
+	if bounds := formatBounds(ts); bounds != "" {
+		if ts.inBounds(date) {
+			fmt.Fprintf(w, "%s: %s is within them\n", bounds, day)
+		} else {
+			fmt.Fprintf(w, "%s: %s is outside them\n", bounds, day)
+			reasons = append(reasons, day+" is outside its From:, Until: and Count: limits")
+		}
+	}
+
+	if ts.due(date) && ts.excepted(date) {
+		fmt.Fprintf(w, "Except: %s is excepted\n", day)
+		reasons = append(reasons, day+" is excepted")
+	}
+
+	if ts.timeOfDay != 0 {
+		if ts.timeReached(now, cfg.location) {
+			fmt.Fprintf(w, "Time: due from %s %s, which has passed\n", formatTimeOfDay(ts.timeOfDay), loc)
+		} else {
+			fmt.Fprintf(w, "Time: due from %s %s, which has not come yet\n", formatTimeOfDay(ts.timeOfDay), loc)
+			reasons = append(reasons, "its time of day has not come yet")
+		}
+	}
+
+	switch {
+	case tmpl.teamID == "":
+		fmt.Fprintln(w, "Team: none, so no run creates issues from it")
+	case teamName == "":
+		fmt.Fprintf(w, "Team: %s, which no longer exists\n", tmpl.teamID)
+	default:
+		fmt.Fprintf(w, "Team: %s; runs for other teams skip it\n", teamName)
+	}
+
+	var lastRun time.Time
+	if cfg.statePath != "" {
+		state, err := loadRunState(cfg.statePath)
+		if err != nil {
+			return fmt.Errorf("failed to load catch-up state: %w", err)
+		}
+		lastRun = state.LastRun[tmpl.teamID]
+	}
+	due := dueOccurrences(tmpl, cfg, lastRun)
+	if cfg.statePath != "" {
+		missed := due.missed()
+		switch {
+		case lastRun.IsZero():
+			fmt.Fprintf(w, "Catch-up: %s has no run of the team recorded\n", cfg.statePath)
+		case ts.catchUp == catchUpSkip:
+			fmt.Fprintln(w, "Catch-up: skip, so missed occurrences are ignored")
+		case len(missed) == 0:
+			fmt.Fprintf(w, "Catch-up: nothing missed since the last run at %s\n", lastRun.In(loc).Format("2006-01-02 15:04"))
+		default:
+			fmt.Fprintf(w, "Catch-up: missed on %s since the last run at %s\n", formatDates(missed), lastRun.In(loc).Format("2006-01-02 15:04"))
+		}
+	}
+
+	switch {
+	case tmpl.teamID == "":
+		fmt.Fprintf(w, "No issue is created from it on %s: it belongs to no team.\n", day)
+	case teamName == "":
+		fmt.Fprintf(w, "No issue is created from it on %s: its team no longer exists.\n", day)
+	case len(due.occurrences) == 0:
+		fmt.Fprintf(w, "No issue is created from it on %s: %s.\n", day, strings.Join(reasons, "; "))
+	default:
+		// The same check as a run's, over the same window.
+		created, err := getTemplateCreatedIssueCounts(ctx, q, tmpl.teamID, due.windowStart, due.windowEnd)
+		if err != nil {
+			return err
+		}
+		toCreate := due.toCreate(created[tmpl.id])
+		fmt.Fprintf(w, "Already created: %d from it since %s\n", created[tmpl.id], due.windowStart.Format("2006-01-02 15:04 MST"))
+		switch {
+		case len(toCreate) == 0 && len(due.toCreate(0)) == 1:
+			fmt.Fprintf(w, "No issue is created from it on %s: the issue for %s was already created.\n", day, formatDates(due.toCreate(0)))
+		case len(toCreate) == 0:
+			fmt.Fprintf(w, "No issue is created from it on %s: the issues for %s were already created.\n", day, formatDates(due.toCreate(0)))
+		case len(toCreate) == 1 && toCreate[0].Equal(due.today):
+			fmt.Fprintf(w, "A run for %s on %s creates an issue from it.\n", teamName, day)
+		default:
+			fmt.Fprintf(w, "A run for %s on %s creates issues from it for %s.\n", teamName, day, formatDates(toCreate))
+		}
+	}
+	return nil
+}
+
+// lookUpTemplate returns the template with the ID ref, or else the only one
+// named ref, ignoring case.
+func lookUpTemplate(templates []issueTemplate, ref string) (issueTemplate, error) {
+	var found []issueTemplate
+	for _, t := range templates {
+		if t.id == ref {
+			return t, nil
+		}
+		if strings.EqualFold(t.name, ref) {
+			found = append(found, t)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return issueTemplate{}, fmt.Errorf("no template found")
+	case 1:
+		return found[0], nil
+	default:
+		ids := make([]string, len(found))
+		for i, t := range found {
+			ids[i] = t.id
+		}
+		return issueTemplate{}, fmt.Errorf("%d templates have this name, use an ID instead: %s", len(found), strings.Join(ids, ", "))
+	}
+}
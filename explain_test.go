@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestExplain(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	now := time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC) // Monday
+	fake.now = func() time.Time { return now }
+	cfg := config{location: time.UTC, clock: fixedClock(now)}
+	weekly := fake.addTemplate("team-1", "Weekly", "Recurrence: Mon\nRecurrence: 15\nRecurrence: Mnday\nTime: 09:00")
+	fake.addTemplate("team-1", "Closed", "Recurrence: daily\nExcept: 2026-03-02")
+	fake.addTemplate("team-1", "Twin", "Recurrence: daily")
+	fake.addTemplate("team-1", "twin", "Recurrence: daily")
+	fake.addTemplate("", "Teamless", "Recurrence: daily")
+
+	explainTo := func(cfg config, ref string) string {
+		t.Helper()
+		var b strings.Builder
+		assert.NoError(t, explain(context.Background(), q, cfg, &b, ref))
+		return b.String()
+	}
+
+	out := explainTo(cfg, "weekly")
+	assert.Contains(t, out, `Template "Weekly" (`+weekly+`) on Monday, 2026-03-02, in UTC`)
+	assert.Contains(t, out, "  Every Monday: matches\n")
+	assert.Contains(t, out, "  Day 15 of every month: does not match\n")
+	assert.Contains(t, out, "  ignored **MALFORMED**: Recurrence: Mnday")
+	assert.Contains(t, out, "Time: due from 09:00 UTC, which has passed\n")
+	assert.Contains(t, out, "Team: Test Team; runs for other teams skip it\n")
+	assert.Contains(t, out, "A run for Test Team on 2026-03-02 creates an issue from it.\n")
+
+	early := cfg
+	early.clock = fixedClock(now.Add(-2 * time.Hour))
+	assert.Contains(t, explainTo(early, weekly), "No issue is created from it on 2026-03-02: its time of day has not come yet.\n")
+
+	tuesday := cfg
+	tuesday.clock = dateClock(time.Date(2026, time.March, 3, 0, 0, 0, 0, time.UTC))
+	out = explainTo(tuesday, weekly)
+	assert.Contains(t, out, "  no line matches\n")
+	assert.Contains(t, out, "No issue is created from it on 2026-03-03: the schedule does not put it on 2026-03-03.\n")
+
+	assert.Contains(t, explainTo(cfg, "Closed"), "No issue is created from it on 2026-03-02: 2026-03-02 is excepted.\n")
+	assert.Contains(t, explainTo(cfg, "Teamless"), "Team: none, so no run creates issues from it\n")
+
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", time.Time{}, nil))
+	assert.Contains(t, explainTo(cfg, weekly), "No issue is created from it on 2026-03-02: the issue for 2026-03-02 was already created.\n")
+
+	var b strings.Builder
+	assert.EqualError(t, explain(context.Background(), q, cfg, &b, "Missing"), "no template found")
+	assert.Error(t, explain(context.Background(), q, cfg, &b, "twin"))
+}
+
+func TestExplain_CatchUp(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	now := time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC) // Monday
+	fake.now = func() time.Time { return now }
+	lastRun := time.Date(2026, time.February, 27, 10, 0, 0, 0, time.UTC) // Friday
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	assert.NoError(t, runState{LastRun: map[string]time.Time{"team-1": lastRun}}.save(statePath))
+	cfg := config{location: time.UTC, clock: fixedClock(now), statePath: statePath, maxCatchUpDays: 7}
+	fake.addTemplate("team-1", "Weekend", "Recurrence: Sat\nRecurrence: Sun\nCatchUp: all")
+
+	var b strings.Builder
+	assert.NoError(t, explain(context.Background(), q, cfg, &b, "Weekend"))
+	assert.Contains(t, b.String(), "Catch-up: missed on 2026-02-28, 2026-03-01 since the last run at 2026-02-27 10:00\n")
+	assert.Contains(t, b.String(), "A run for Test Team on 2026-03-02 creates issues from it for 2026-02-28, 2026-03-01.\n")
+
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", lastRun, nil))
+	b.Reset()
+	assert.NoError(t, explain(context.Background(), q, cfg, &b, "Weekend"))
+	assert.Contains(t, b.String(), "No issue is created from it on 2026-03-02: the issues for 2026-02-28, 2026-03-01 were already created.\n")
+}
@@ -0,0 +1,490 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLinear is an in-memory stand-in for the parts of Linear's GraphQL API
+// that linear-future and its tests use. It dispatches on the first field of
+// the operation and reads arguments from the variables, so it only understands
+// queries shaped like the ones in this package.
+type fakeLinear struct {
+	mu        sync.Mutex
+	teams     []*fakeTeam
+	templates []*fakeTemplate
+	issues    []*fakeIssue
+	relations []*fakeRelation
+	nextID    int
+	pageSize  int
+	now       func() time.Time
+	requests  int
+	failures  []fakeFailure      // answered in order before any real responses
+	onRequest func(field string) // called with each request's root field, if set
+}
+
+// fakeFailure is a canned failure response. With a code, the body is a
+// GraphQL error with that extensions.code.
+type fakeFailure struct {
+	hang   bool // never answer, until the client gives up
+	status int
+	header map[string]string
+	code   string
+}
+
+type fakeTeam struct {
+	id, name string
+}
+
+type fakeTemplate struct {
+	id, name, description, teamID string
+	templateData                  string // JSON, served double-encoded like Linear does
+}
+
+type fakeIssue struct {
+	id, teamID, title, parentID, templateID string
+	createdAt                               time.Time
+	comments                                []string
+}
+
+type fakeRelation struct {
+	id, issueID, relatedIssueID, relType string
+}
+
+type fakeError struct {
+	message, code string
+}
+
+func (e *fakeError) Error() string { return e.message }
+
+// newFakeLinear starts a fake Linear server with a single team and returns it
+// with a client pointed at it.
+func newFakeLinear(t *testing.T) (*fakeLinear, q) {
+	t.Helper()
+	f := &fakeLinear{
+		teams:    []*fakeTeam{{id: "team-1", name: "Test Team"}},
+		pageSize: 50,
+		now:      time.Now,
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, q{token: "lin_api_fake", endpoint: srv.URL, client: srv.Client()}
+}
+
+var fakeRootFieldRx = regexp.MustCompile(`^[^{]*\{\s*(\w+)`)
+
+func (f *fakeLinear) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") == "" {
+		http.Error(w, `{"errors":[{"message":"Authentication required","extensions":{"code":"AUTHENTICATION_ERROR"}}]}`, http.StatusBadRequest)
+		return
+	}
+	var req struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m := fakeRootFieldRx.FindStringSubmatch(req.Query)
+	if m == nil {
+		http.Error(w, "no operation", http.StatusBadRequest)
+		return
+	}
+
+	if f.onRequest != nil {
+		f.onRequest(m[1])
+	}
+
+	f.mu.Lock()
+	f.requests++
+	if len(f.failures) > 0 {
+		failure := f.failures[0]
+		f.failures = f.failures[1:]
+		f.mu.Unlock()
+		if failure.hang {
+			<-r.Context().Done()
+			return
+		}
+		for k, v := range failure.header {
+			w.Header().Set(k, v)
+		}
+		w.WriteHeader(failure.status)
+		if failure.code != "" {
+			json.NewEncoder(w).Encode(map[string]any{
+				"errors": []any{map[string]any{"message": failure.code, "extensions": map[string]any{"code": failure.code}}},
+			})
+		}
+		return
+	}
+	data, err := f.resolve(m[1], req.Variables)
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		code := "INVALID_INPUT"
+		if fe, ok := err.(*fakeError); ok {
+			code = fe.code
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"data": nil,
+			"errors": []any{map[string]any{
+				"message":    err.Error(),
+				"path":       []string{m[1]},
+				"extensions": map[string]any{"code": code},
+			}},
+		})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{m[1]: data}})
+}
+
+func (f *fakeLinear) resolve(field string, vars map[string]any) (any, error) {
+	switch field {
+	case "teams":
+		var nodes []any
+		for _, team := range f.teams {
+			if name, ok := vars["teamName"].(string); ok && name != team.name {
+				continue
+			}
+			nodes = append(nodes, map[string]any{"id": team.id, "name": team.name})
+		}
+		return map[string]any{"nodes": nodes, "pageInfo": map[string]any{"hasNextPage": false}}, nil
+	case "templates":
+		var out []any
+		for _, tmpl := range f.templates {
+			out = append(out, map[string]any{
+				"id":           tmpl.id,
+				"name":         tmpl.name,
+				"description":  tmpl.description,
+				"templateData": tmpl.templateData,
+				"team":         map[string]any{"id": tmpl.teamID},
+			})
+		}
+		return out, nil
+	case "templateCreate":
+		input, _ := vars["input"].(map[string]any)
+		data, _ := json.Marshal(input["templateData"])
+		tmpl := &fakeTemplate{
+			id:           f.newID("template"),
+			name:         fmt.Sprint(input["name"]),
+			description:  fmt.Sprint(input["description"]),
+			teamID:       fmt.Sprint(input["teamId"]),
+			templateData: string(data),
+		}
+		f.templates = append(f.templates, tmpl)
+		return map[string]any{"success": true, "template": map[string]any{"id": tmpl.id}}, nil
+	case "templateDelete":
+		for i, tmpl := range f.templates {
+			if tmpl.id == vars["id"] {
+				f.templates = append(f.templates[:i], f.templates[i+1:]...)
+				return map[string]any{"success": true}, nil
+			}
+		}
+		return nil, &fakeError{"Entity not found: Template", "INVALID_INPUT"}
+	case "issues":
+		return f.resolveIssues(vars)
+	case "issue":
+		return f.resolveIssue(vars)
+	case "issueCreate":
+		return f.resolveIssueCreate(vars)
+	case "issueUpdate":
+		issue := f.issue(vars["id"])
+		if issue == nil {
+			return nil, &fakeError{"Entity not found: Issue", "INVALID_INPUT"}
+		}
+		if title, ok := vars["title"].(string); ok {
+			issue.title = title
+		}
+		return map[string]any{"success": true}, nil
+	case "issueDelete":
+		issue := f.issue(vars["id"])
+		if issue == nil {
+			return nil, &fakeError{"Entity not found: Issue", "INVALID_INPUT"}
+		}
+		f.deleteIssue(issue.id)
+		return map[string]any{"success": true}, nil
+	case "commentCreate":
+		input, _ := vars["input"].(map[string]any)
+		issue := f.issue(input["issueId"])
+		if issue == nil {
+			return nil, &fakeError{"Entity not found: Issue", "INVALID_INPUT"}
+		}
+		issue.comments = append(issue.comments, fmt.Sprint(input["body"]))
+		return map[string]any{"success": true}, nil
+	case "issueRelationCreate":
+		input, _ := vars["input"].(map[string]any)
+		rel := &fakeRelation{
+			issueID:        fmt.Sprint(input["issueId"]),
+			relatedIssueID: fmt.Sprint(input["relatedIssueId"]),
+			relType:        fmt.Sprint(input["type"]),
+		}
+		if f.issue(rel.issueID) == nil || f.issue(rel.relatedIssueID) == nil {
+			return nil, &fakeError{"Entity not found: Issue", "INVALID_INPUT"}
+		}
+		for _, existing := range f.relations {
+			if existing.issueID == rel.issueID && existing.relatedIssueID == rel.relatedIssueID && existing.relType == rel.relType {
+				return nil, &fakeError{"Relation already exists", "INVALID_INPUT"}
+			}
+		}
+		rel.id = f.newID("relation")
+		f.relations = append(f.relations, rel)
+		return map[string]any{"success": true}, nil
+	default:
+		return nil, &fakeError{fmt.Sprintf("fake Linear does not implement %s", field), "GRAPHQL_VALIDATION_FAILED"}
+	}
+}
+
+func (f *fakeLinear) resolveIssues(vars map[string]any) (any, error) {
+	var matched []*fakeIssue
+	for _, issue := range f.issues {
+		if teamID, ok := vars["teamID"].(string); ok && issue.teamID != teamID {
+			continue
+		}
+		if title, ok := vars["title"].(map[string]any); ok && !strings.Contains(issue.title, fmt.Sprint(title["contains"])) {
+			continue
+		}
+		if start, ok := vars["start"].(string); ok && issue.createdAt.Before(mustParseRFC3339(start)) {
+			continue
+		}
+		if end, ok := vars["end"].(string); ok && !issue.createdAt.Before(mustParseRFC3339(end)) {
+			continue
+		}
+		matched = append(matched, issue)
+	}
+	nodes, pageInfo := f.page(matched, vars["after"])
+	return map[string]any{"nodes": nodes, "pageInfo": pageInfo}, nil
+}
+
+func (f *fakeLinear) resolveIssue(vars map[string]any) (any, error) {
+	id := vars["id"]
+	if id == nil {
+		id = vars["issueId"]
+	}
+	issue := f.issue(id)
+	if issue == nil {
+		return nil, &fakeError{"Entity not found: Issue", "INVALID_INPUT"}
+	}
+
+	var children []*fakeIssue
+	for _, child := range f.issues {
+		if child.parentID == issue.id {
+			children = append(children, child)
+		}
+	}
+	childNodes, childPageInfo := f.page(children, vars["after"])
+
+	relations, relationsPageInfo := f.relationPage(issue.id, false, vars["relationsAfter"])
+	inverseRelations, inverseRelationsPageInfo := f.relationPage(issue.id, true, vars["relationsAfter"])
+
+	var comments []any
+	for _, body := range issue.comments {
+		comments = append(comments, map[string]any{"body": body})
+	}
+
+	return map[string]any{
+		"id":               issue.id,
+		"title":            issue.title,
+		"children":         map[string]any{"nodes": childNodes, "pageInfo": childPageInfo},
+		"comments":         map[string]any{"nodes": comments, "pageInfo": map[string]any{"hasNextPage": false}},
+		"relations":        map[string]any{"nodes": relations, "pageInfo": relationsPageInfo},
+		"inverseRelations": map[string]any{"nodes": inverseRelations, "pageInfo": inverseRelationsPageInfo},
+	}, nil
+}
+
+// relationPage returns one page of the relations of an issue, or of its
+// inverse relations, after the given cursor.
+func (f *fakeLinear) relationPage(issueID string, inverse bool, after any) ([]any, map[string]any) {
+	var matched []*fakeRelation
+	for _, rel := range f.relations {
+		if !inverse && rel.issueID == issueID || inverse && rel.relatedIssueID == issueID {
+			matched = append(matched, rel)
+		}
+	}
+	start := 0
+	if cursor, ok := after.(string); ok {
+		start, _ = strconv.Atoi(cursor)
+	}
+	// The cursor is shared by relations and inverseRelations.
+	start = min(start, len(matched))
+	end := min(start+f.pageSize, len(matched))
+	nodes := []any{}
+	for _, rel := range matched[start:end] {
+		nodes = append(nodes, map[string]any{
+			"id":           rel.id,
+			"type":         rel.relType,
+			"issue":        map[string]any{"id": rel.issueID},
+			"relatedIssue": map[string]any{"id": rel.relatedIssueID},
+		})
+	}
+	return nodes, map[string]any{"hasNextPage": end < len(matched), "endCursor": strconv.Itoa(end)}
+}
+
+func (f *fakeLinear) resolveIssueCreate(vars map[string]any) (any, error) {
+	// Created from a template: the template's title and children are applied.
+	if templateID, ok := vars["templateId"].(string); ok {
+		var tmpl *fakeTemplate
+		for _, t := range f.templates {
+			if t.id == templateID {
+				tmpl = t
+			}
+		}
+		if tmpl == nil {
+			return nil, &fakeError{"Entity not found: Template", "INVALID_INPUT"}
+		}
+		var td struct {
+			Title    string `json:"title"`
+			Children []struct {
+				Title string `json:"title"`
+			} `json:"children"`
+		}
+		json.Unmarshal([]byte(tmpl.templateData), &td)
+		teamID := fmt.Sprint(vars["teamId"])
+		parent := f.addIssue(teamID, td.Title, "", tmpl.id)
+		if createdAt, ok := vars["createdAt"].(string); ok {
+			parent.createdAt = mustParseRFC3339(createdAt)
+		}
+		for _, child := range td.Children {
+			f.addIssue(teamID, child.Title, parent.id, "")
+		}
+		return map[string]any{"success": true, "issue": map[string]any{"id": parent.id}}, nil
+	}
+
+	input, _ := vars["input"].(map[string]any)
+	parentID, _ := input["parentId"].(string)
+	if parentID != "" && f.issue(parentID) == nil {
+		return nil, &fakeError{"Entity not found: Issue", "INVALID_INPUT"}
+	}
+	issue := f.addIssue(fmt.Sprint(input["teamId"]), fmt.Sprint(input["title"]), parentID, "")
+	return map[string]any{"success": true, "issue": map[string]any{"id": issue.id}}, nil
+}
+
+func (f *fakeLinear) addIssue(teamID, title, parentID, templateID string) *fakeIssue {
+	issue := &fakeIssue{
+		id:         f.newID("issue"),
+		teamID:     teamID,
+		title:      title,
+		parentID:   parentID,
+		templateID: templateID,
+		createdAt:  f.now(),
+	}
+	f.issues = append(f.issues, issue)
+	return issue
+}
+
+func (f *fakeLinear) issue(id any) *fakeIssue {
+	for _, issue := range f.issues {
+		if issue.id == id {
+			return issue
+		}
+	}
+	return nil
+}
+
+// deleteIssue removes an issue along with its sub-issues and relations.
+func (f *fakeLinear) deleteIssue(id string) {
+	var issues []*fakeIssue
+	var children []string
+	for _, issue := range f.issues {
+		switch {
+		case issue.id == id:
+		case issue.parentID == id:
+			children = append(children, issue.id)
+			issues = append(issues, issue)
+		default:
+			issues = append(issues, issue)
+		}
+	}
+	f.issues = issues
+
+	var relations []*fakeRelation
+	for _, rel := range f.relations {
+		if rel.issueID != id && rel.relatedIssueID != id {
+			relations = append(relations, rel)
+		}
+	}
+	f.relations = relations
+
+	for _, child := range children {
+		f.deleteIssue(child)
+	}
+}
+
+// page returns one page of issues after the given cursor, which is the index to continue from.
+func (f *fakeLinear) page(issues []*fakeIssue, after any) ([]any, map[string]any) {
+	start := 0
+	if cursor, ok := after.(string); ok {
+		start, _ = strconv.Atoi(cursor)
+	}
+	end := min(start+f.pageSize, len(issues))
+	nodes := []any{}
+	for _, issue := range issues[start:end] {
+		relations, relationsPageInfo := f.relationPage(issue.id, false, nil)
+		inverseRelations, inverseRelationsPageInfo := f.relationPage(issue.id, true, nil)
+		node := map[string]any{
+			"id":               issue.id,
+			"title":            issue.title,
+			"relations":        map[string]any{"nodes": relations, "pageInfo": relationsPageInfo},
+			"inverseRelations": map[string]any{"nodes": inverseRelations, "pageInfo": inverseRelationsPageInfo},
+		}
+		if issue.templateID != "" {
+			node["lastAppliedTemplate"] = map[string]any{"id": issue.templateID}
+		} else {
+			node["lastAppliedTemplate"] = nil
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, map[string]any{"hasNextPage": end < len(issues), "endCursor": strconv.Itoa(end)}
+}
+
+func (f *fakeLinear) newID(kind string) string {
+	f.nextID++
+	return fmt.Sprintf("%s-%d", kind, f.nextID)
+}
+
+func mustParseRFC3339(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// addTemplate adds a template directly, bypassing the API.
+func (f *fakeLinear) addTemplate(teamID, name, description string, subIssueTitles ...string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	td := map[string]any{"title": name}
+	if len(subIssueTitles) > 0 {
+		var children []any
+		for _, title := range subIssueTitles {
+			children = append(children, map[string]any{"title": title})
+		}
+		td["children"] = children
+	}
+	data, _ := json.Marshal(td)
+	tmpl := &fakeTemplate{id: f.newID("template"), name: name, description: description, teamID: teamID, templateData: string(data)}
+	f.templates = append(f.templates, tmpl)
+	return tmpl.id
+}
+
+// issuesFromTemplate returns the issues created from a template, oldest first.
+func (f *fakeLinear) issuesFromTemplate(templateID string) []*fakeIssue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []*fakeIssue
+	for _, issue := range f.issues {
+		if issue.templateID == templateID {
+			out = append(out, issue)
+		}
+	}
+	return out
+}
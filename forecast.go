@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// forecast is the issues runs would create over a range of days.
+type forecast struct {
+	From   string             `json:"from"`
+	Until  string             `json:"until"`
+	Issues []forecastIssue    `json:"issues"` // by date, then in template order
+	Counts []forecastTemplate `json:"counts"` // most issues first
+}
+
+type forecastIssue struct {
+	Date       string `json:"date"`
+	TemplateID string `json:"templateId"`
+	Template   string `json:"template"`
+	TeamID     string `json:"teamId"`
+	Team       string `json:"team"` // name
+	Title      string `json:"title"`
+}
+
+type forecastTemplate struct {
+	TemplateID string `json:"templateId"`
+	Template   string `json:"template"`
+	TeamID     string `json:"teamId"`
+	Team       string `json:"team"` // name
+	Count      int    `json:"count"`
+}
+
+// runForecast prints the issues runs for the named teams, or for all teams,
+// would create in the cfg.forecastDays days from today.
+func runForecast(ctx context.Context, token string, cfg config, teamNames []string) int {
+	if cfg.forecastDays < 1 {
+		fmt.Fprintln(os.Stderr, "invalid -days: must be at least 1")
+		return 2
+	}
+	q := cfg.newQ(token)
+	templates, err := getTemplates(ctx, q)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list templates: %v\n", err)
+		return 1
+	}
+	teams, err := getTeams(ctx, q)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list teams: %v\n", err)
+		return 1
+	}
+	for _, name := range teamNames {
+		if !slices.ContainsFunc(teams, func(t team) bool { return t.name == name }) {
+			fmt.Fprintf(os.Stderr, "failed to resolve team name %q: no team found\n", name)
+			return 1
+		}
+	}
+
+	f := forecastIssues(templates, teams, teamNames, cfg, cfg.forecastDays)
+	switch cfg.format {
+	case "json", "yaml":
+		err = writeFormatted(os.Stdout, cfg.format, f)
+	case "ics":
+		err = writeForecastICS(os.Stdout, f, time.Now())
+	default:
+		writeForecastText(os.Stdout, f)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write forecast: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// forecastIssues returns the days on which the templates of the named teams
+// (all teams if there are none) fire, for days days from each template's
+// today by cfg's clock. Templates without a team are left out, as no run
+// creates issues from them.
+//
+// Every day a template fires on counts, whatever its Time: line says and
+// whether or not its issue has already been created.
+func forecastIssues(templates []issueTemplate, teams []team, teamNames []string, cfg config, days int) forecast {
+	teamNameByID := map[string]string{}
+	for _, t := range teams {
+		teamNameByID[t.id] = t.name
+	}
+
+	// The range is the default time zone's, widened by templates in time
+	// zones that are a day ahead or behind.
+	from := civilDate(cfg.now(cfg.location))
+	until := from.AddDate(0, 0, days-1)
+	f := forecast{
+		Issues: []forecastIssue{},
+		Counts: []forecastTemplate{},
+	}
+	for _, tmpl := range templates {
+		team, ok := teamNameByID[tmpl.teamID]
+		if !ok || len(teamNames) > 0 && !slices.Contains(teamNames, team) {
+			continue
+		}
+		ts := parseTemplateSchedule(tmpl.description, cfg.calendars)
+		count := forecastTemplate{TemplateID: tmpl.id, Template: tmpl.name, TeamID: tmpl.teamID, Team: team}
+		title := tmpl.issueTitle
+		if title == "" {
+			title = tmpl.name
+		}
+		today := ts.today(cfg.now(ts.locationOr(cfg.location)), cfg.location)
+		if today.Before(from) {
+			from = today
+		}
+		if last := today.AddDate(0, 0, days-1); last.After(until) {
+			until = last
+		}
+		for i := range days {
+			d := today.AddDate(0, 0, i)
+			if !ts.matches(d) {
+				continue
+			}
+			f.Issues = append(f.Issues, forecastIssue{
+				Date:       d.Format("2006-01-02"),
+				TemplateID: tmpl.id,
+				Template:   tmpl.name,
+				TeamID:     tmpl.teamID,
+				Team:       team,
+				Title:      title,
+			})
+			count.Count++
+		}
+		f.Counts = append(f.Counts, count)
+	}
+	f.From = from.Format("2006-01-02")
+	f.Until = until.Format("2006-01-02")
+	slices.SortStableFunc(f.Issues, func(a, b forecastIssue) int { return strings.Compare(a.Date, b.Date) })
+	slices.SortStableFunc(f.Counts, func(a, b forecastTemplate) int { return b.Count - a.Count })
+	return f
+}
+
+func writeForecastText(w io.Writer, f forecast) {
+	fmt.Fprintf(w, "%d issues from %s to %s\n", len(f.Issues), f.From, f.Until)
+	date := ""
+	for _, issue := range f.Issues {
+		if issue.Date != date {
+			date = issue.Date
+			d, _ := time.Parse("2006-01-02", date)
+			fmt.Fprintf(w, "\n%s %s\n", date, d.Weekday())
+		}
+		fmt.Fprintf(w, "  %s (%s, template %q)\n", issue.Title, issue.Team, issue.Template)
+	}
+
+	if len(f.Counts) > 0 {
+		fmt.Fprintf(w, "\nPer template:\n")
+	}
+	for _, c := range f.Counts {
+		fmt.Fprintf(w, "  %4d  %s (%s)\n", c.Count, c.Template, c.Team)
+	}
+}
+
+// writeForecastICS writes the forecast as an iCalendar (RFC 5545) file with an
+// all-day event per issue, stamped with now.
+func writeForecastICS(w io.Writer, f forecast, now time.Time) error {
+	var b strings.Builder
+	line := func(format string, args ...any) {
+		b.WriteString(foldICSLine(fmt.Sprintf(format, args...)))
+		b.WriteString("\r\n")
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//linear-future//forecast//EN")
+	line("CALSCALE:GREGORIAN")
+	line("X-WR-CALNAME:Issues from Linear templates")
+	stamp := now.UTC().Format("20060102T150405Z")
+	for _, issue := range f.Issues {
+		d, err := time.Parse("2006-01-02", issue.Date)
+		if err != nil {
+			return err
+		}
+		line("BEGIN:VEVENT")
+		// Stable UIDs let calendar apps update events when the forecast is
+		// fetched again.
+		line("UID:%s-%s@linear-future", issue.TemplateID, d.Format("20060102"))
+		line("DTSTAMP:%s", stamp)
+		line("DTSTART;VALUE=DATE:%s", d.Format("20060102"))
+		line("DTEND;VALUE=DATE:%s", d.AddDate(0, 0, 1).Format("20060102"))
+		line("SUMMARY:%s", escapeICSText(issue.Title))
+		line("DESCRIPTION:%s", escapeICSText(fmt.Sprintf("Created by linear-future from template %q of team %s.", issue.Template, issue.Team)))
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeICSText escapes an iCalendar TEXT value.
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// foldICSLine splits a content line into lines of at most 75 bytes, without
+// splitting UTF-8 sequences; continuation lines start with a space.
+func foldICSLine(s string) string {
+	var b strings.Builder
+	limit := 75
+	for len(s) > limit {
+		n := limit
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		b.WriteString(s[:n])
+		b.WriteString("\r\n ")
+		s = s[n:]
+		limit = 74 // after the leading space
+	}
+	b.WriteString(s)
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestForecastIssues(t *testing.T) {
+	teams := []team{{id: "team-1", name: "Engineering"}, {id: "team-2", name: "Design"}}
+	templates := []issueTemplate{
+		{id: "t1", name: "Standup", teamID: "team-1", description: "Recurrence: Mon\nRecurrence: Wed"},
+		{id: "t2", name: "Review", teamID: "team-2", issueTitle: "Design review", description: "Recurrence: Tue\nExcept: 2026-03-10"},
+		{id: "t3", name: "Never", teamID: "team-1", description: "At: 2025-01-01"},
+		{id: "t4", name: "Teamless", description: "Recurrence: daily"},
+	}
+	now := time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC) // Monday
+	cfg := config{location: time.UTC, clock: fixedClock(now)}
+
+	f := forecastIssues(templates, teams, nil, cfg, 14)
+	assert.Equal(t, "2026-03-02", f.From)
+	assert.Equal(t, "2026-03-15", f.Until)
+	var got []string
+	for _, issue := range f.Issues {
+		got = append(got, issue.Date+" "+issue.Title)
+	}
+	assert.Equal(t, []string{
+		"2026-03-02 Standup",
+		"2026-03-03 Design review",
+		"2026-03-04 Standup",
+		"2026-03-09 Standup",
+		"2026-03-11 Standup",
+	}, got)
+	assert.Equal(t, []forecastTemplate{
+		{TemplateID: "t1", Template: "Standup", TeamID: "team-1", Team: "Engineering", Count: 4},
+		{TemplateID: "t2", Template: "Review", TeamID: "team-2", Team: "Design", Count: 1},
+		{TemplateID: "t3", Template: "Never", TeamID: "team-1", Team: "Engineering", Count: 0},
+	}, f.Counts)
+
+	f = forecastIssues(templates, teams, []string{"Design"}, cfg, 14)
+	assert.Equal(t, 1, len(f.Issues))
+	assert.Equal(t, 1, len(f.Counts))
+
+	var b strings.Builder
+	writeForecastText(&b, forecastIssues(templates, teams, nil, cfg, 3))
+	assert.Equal(t, `3 issues from 2026-03-02 to 2026-03-04
+
+2026-03-02 Monday
+  Standup (Engineering, template "Standup")
+
+2026-03-03 Tuesday
+  Design review (Design, template "Review")
+
+2026-03-04 Wednesday
+  Standup (Engineering, template "Standup")
+
+Per template:
+     2  Standup (Engineering)
+     1  Review (Design)
+     0  Never (Engineering)
+`, b.String())
+}
+
+func TestForecastIssues_TimeZones(t *testing.T) {
+	teams := []team{{id: "team-1", name: "Engineering"}}
+	templates := []issueTemplate{
+		{id: "t1", name: "Local", teamID: "team-1", description: "Recurrence: daily"},
+		{id: "t2", name: "Auckland", teamID: "team-1", description: "Recurrence: daily\nTimezone: Pacific/Auckland"},
+	}
+	// Sunday evening in UTC is already Monday in Auckland.
+	now := time.Date(2026, time.March, 1, 20, 0, 0, 0, time.UTC)
+	cfg := config{location: time.UTC, clock: fixedClock(now)}
+
+	f := forecastIssues(templates, teams, nil, cfg, 2)
+	assert.Equal(t, "2026-03-01", f.From)
+	assert.Equal(t, "2026-03-03", f.Until)
+	var got []string
+	for _, issue := range f.Issues {
+		got = append(got, issue.Date+" "+issue.Title)
+	}
+	assert.Equal(t, []string{"2026-03-01 Local", "2026-03-02 Local", "2026-03-02 Auckland", "2026-03-03 Auckland"}, got)
+}
+
+func TestWriteForecastICS(t *testing.T) {
+	f := forecast{Issues: []forecastIssue{
+		{Date: "2026-03-02", TemplateID: "t1", Template: "Standup", Team: "Engineering", Title: "Standup; notes, etc."},
+		{Date: "2026-03-31", TemplateID: "t2", Template: "Review", Team: "Design", Title: strings.Repeat("Überprüfung ", 10)},
+	}}
+	var b strings.Builder
+	assert.NoError(t, writeForecastICS(&b, f, time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)))
+	ics := b.String()
+	assert.Contains(t, ics, "BEGIN:VEVENT\r\nUID:t1-20260302@linear-future\r\nDTSTAMP:20260301T120000Z\r\nDTSTART;VALUE=DATE:20260302\r\nDTEND;VALUE=DATE:20260303\r\nSUMMARY:Standup\\; notes\\, etc.\r\n")
+	for _, line := range strings.Split(ics, "\r\n") {
+		assert.True(t, len(line) <= 75, "line too long: %q", line)
+	}
+
+	// It reads back as the same days.
+	cal, err := readICS(strings.NewReader(ics), time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, calendar{"2026-03-02": true, "2026-03-31": true}, cal)
+	var summary string
+	for _, line := range unfoldICS(strings.NewReader(ics)) {
+		if after, ok := strings.CutPrefix(line, "SUMMARY:"); ok {
+			summary = after
+		}
+	}
+	assert.Equal(t, strings.Repeat("Überprüfung ", 10), summary)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestWriteYAML(t *testing.T) {
+	type item struct {
+		Name  string         `json:"name"`
+		Tags  []string       `json:"tags"`
+		Extra map[string]any `json:"extra"`
+		Count int            `json:"count,omitempty"`
+	}
+	v := []item{
+		{Name: "Weekly sync", Tags: []string{"2026-03-02", "yes", "a: b", "plain text"}, Extra: map[string]any{}},
+		{Name: "", Tags: []string{}, Extra: map[string]any{"nested": []any{map[string]any{"k": 1}, []any{true, nil}}}, Count: 3},
+	}
+	var b strings.Builder
+	assert.NoError(t, writeYAML(&b, v))
+	assert.Equal(t, `- name: Weekly sync
+  tags:
+    - "2026-03-02"
+    - yes
+    - 'a: b'
+    - plain text
+  extra: {}
+- name: ""
+  tags: []
+  extra:
+    nested:
+      - k: 1
+      - - true
+        - null
+  count: 3
+`, b.String())
+}
+
+func TestWriteFormatted_JSON(t *testing.T) {
+	var b strings.Builder
+	assert.NoError(t, writeFormatted(&b, "json", map[string]string{"title": "<b> & c"}))
+	assert.Equal(t, "{\n  \"title\": \"<b> & c\"\n}\n", b.String())
+}
@@ -1,65 +1,332 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 )
 
-func createScheduledTeamIssues(token string, teamName string) error {
-	q := q{token}
-	teamID, err := getTeamID(q, teamName)
+// runReport records the issues a run created, so that a run that is stopped
+// part way can say what it has done.
+type runReport struct {
+	created []createdIssue
+}
+
+type createdIssue struct {
+	template   string
+	day        time.Time
+	issueID    string
+	incomplete bool // sub-issue dependencies were not fully set up
+	rolledBack bool // deleted again after setup failed
+}
+
+// add records an issue; it does nothing on a nil report.
+func (r *runReport) add(c createdIssue) *createdIssue {
+	if r == nil {
+		return &c
+	}
+	r.created = append(r.created, c)
+	return &r.created[len(r.created)-1]
+}
+
+func (r *runReport) print(w io.Writer) {
+	if len(r.created) == 0 {
+		fmt.Fprintln(w, "No issues were created")
+		return
+	}
+	fmt.Fprintf(w, "Created %d issues:\n", len(r.created))
+	for _, c := range r.created {
+		fmt.Fprintf(w, "  %s from template %q for %s", c.issueID, c.template, c.day.Format("2006-01-02"))
+		switch {
+		case c.rolledBack:
+			fmt.Fprint(w, " (deleted again after sub-issue setup failed)")
+		case c.incomplete:
+			fmt.Fprint(w, " (sub-issue dependencies not set up)")
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// runTeams creates the due issues of each team. If the run is interrupted or
+// times out, it reports the issues it has created so far.
+func runTeams(ctx context.Context, token string, cfg config, teamNames []string) int {
+	// Issues cannot be dated in the future, so a later run could not tell
+	// they were created already.
+	if !cfg.dryRun && civilDate(cfg.now(cfg.location)).After(civilDate(time.Now().In(cfg.location))) {
+		fmt.Fprintln(os.Stderr, "-date in the future only works with -dry-run")
+		return 2
+	}
+	retCode := 0
+	report := &runReport{}
+	for _, teamName := range teamNames {
+		if ctx.Err() != nil {
+			break
+		}
+		if err := createScheduledTeamIssues(ctx, token, teamName, cfg, report); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			retCode = 1
+		}
+	}
+	if ctx.Err() != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			fmt.Fprintf(os.Stderr, "Run timed out after %s.\n", cfg.runTimeout)
+		} else {
+			fmt.Fprintln(os.Stderr, "Run interrupted.")
+		}
+		report.print(os.Stderr)
+		return 1
+	}
+	return retCode
+}
+
+func createScheduledTeamIssues(ctx context.Context, token string, teamName string, cfg config, report *runReport) error {
+	q := cfg.newQ(token)
+	teamID, err := getTeamID(ctx, q, teamName)
 	if err != nil {
 		return fmt.Errorf("failed to resolve team: %w", err)
 	}
 	fmt.Printf("Team %q resolved to ID %s\n", teamName, teamID)
 
-	today := time.Now().UTC().Truncate(24 * time.Hour)
-	if err := createFromDueTemplates(q, teamID, today); err != nil {
+	var state runState
+	var lastRun time.Time
+	if cfg.statePath != "" {
+		state, err = loadRunState(cfg.statePath)
+		if err != nil {
+			return fmt.Errorf("failed to load catch-up state: %w", err)
+		}
+		lastRun = state.LastRun[teamID]
+	}
+
+	runStart := time.Now()
+	if err := createFromDueTemplates(ctx, q, cfg, teamID, lastRun, report); err != nil {
 		return fmt.Errorf("failed to create issues from templates: %w", err)
 	}
+
+	// Only real runs for the real today count as handled.
+	if cfg.statePath != "" && !cfg.dryRun && cfg.clock == nil {
+		state.LastRun[teamID] = runStart
+		if err := state.save(cfg.statePath); err != nil {
+			return fmt.Errorf("failed to save catch-up state: %w", err)
+		}
+	}
 	return nil
 }
 
-func createFromDueTemplates(q q, teamID string, today time.Time) error {
-	templates, err := getTemplates(q)
+// createFromDueTemplates creates issues from the team's templates that are due
+// now by cfg's clock. Each template's day and time of day are taken in its own
+// time zone.
+//
+// If lastRun is not zero, occurrences missed since then are created too,
+// according to each template's CatchUp: policy; see dueOccurrences.
+//
+// Created issues are recorded in report, which may be nil.
+func createFromDueTemplates(ctx context.Context, q q, cfg config, teamID string, lastRun time.Time, report *runReport) error {
+	templates, err := getTemplates(ctx, q)
 	if err != nil {
 		return err
 	}
 
-	var dueTemplates []issueTemplate
+	var dueTemplates []dueTemplate
 	for _, tmpl := range templates {
 		if tmpl.teamID != teamID {
 			continue
 		}
-		if templateMatchesSchedule(tmpl.description, today) {
+		due := dueOccurrences(tmpl, cfg, lastRun)
+		ts, today := due.ts, due.today
+		if missed := due.missed(); len(missed) > 0 {
+			fmt.Printf("Template %q was due on %s since the last run\n", tmpl.name, formatDates(missed))
+		}
+
+		if ts.matches(today) && !ts.timeReached(due.now, cfg.location) {
+			fmt.Printf("Template %q is due today at %s %s, not yet\n", tmpl.name, formatTimeOfDay(ts.timeOfDay), due.now.Location())
+		} else if ts.matches(today) {
 			fmt.Printf("Template %q is due today\n", tmpl.name)
-			dueTemplates = append(dueTemplates, tmpl)
+		} else if ts.due(today) {
+			fmt.Printf("Template %q is due today, but today is excepted\n", tmpl.name)
+		} else if ts.expired(today) {
+			fmt.Printf("Template %q expired on %s\n", tmpl.name, ts.endBy(today).Format("2006-01-02"))
 		} else {
 			fmt.Printf("Template %q is not due today\n", tmpl.name)
 		}
+		if len(due.occurrences) == 0 {
+			continue
+		}
+		dueTemplates = append(dueTemplates, due)
 	}
 	if len(dueTemplates) == 0 {
 		return nil
 	}
 
-	createdToday, err := getTemplateCreatedIssuesForDay(q, teamID, today)
-	if err != nil {
-		return err
-	}
+	// Templates in different time zones or with different catch-up windows need
+	// different ranges checked for already created issues.
+	type window struct{ start, end int64 }
+	createdByWindow := map[window]map[string]int{}
+	for _, due := range dueTemplates {
+		tmpl := due.tmpl
+		w := window{due.windowStart.Unix(), due.windowEnd.Unix()}
+		created, ok := createdByWindow[w]
+		if !ok {
+			created, err = getTemplateCreatedIssueCounts(ctx, q, teamID, due.windowStart, due.windowEnd)
+			if err != nil {
+				return err
+			}
+			createdByWindow[w] = created
+		}
 
-	for _, tmpl := range dueTemplates {
-		if createdToday[tmpl.id] {
-			fmt.Printf("Template %q already created today, skipping\n", tmpl.name)
+		toCreate := due.toCreate(created[tmpl.id])
+		if len(toCreate) == 0 {
+			fmt.Printf("Template %q already created for %s, skipping\n", tmpl.name, formatDates(due.toCreate(0)))
 			continue
 		}
-		fmt.Printf("Creating issue from template %q\n", tmpl.name)
-		issueID, err := createIssueFromTemplate(q, tmpl.id, teamID)
-		if err != nil {
-			return err
-		}
-		if err := setupSubIssueDependencies(q, issueID); err != nil {
-			return fmt.Errorf("setting up sub-issue dependencies for template %q: %w", tmpl.name, err)
+
+		for _, day := range toCreate {
+			fmt.Printf("Creating issue from template %q for %s\n", tmpl.name, day.Format("2006-01-02"))
+			issueID, err := createIssueFromTemplate(ctx, q, tmpl.id, teamID, issueDate(due.ts, day, cfg.location))
+			if err != nil {
+				return err
+			}
+			created := report.add(createdIssue{template: tmpl.name, day: day, issueID: issueID, incomplete: true})
+			if err := setupSubIssueDependencies(ctx, q, issueID); err != nil {
+				err = fmt.Errorf("setting up sub-issue dependencies for template %q: %w", tmpl.name, err)
+				if !cfg.rollback {
+					return err
+				}
+				if rbErr := rollBack(ctx, q, issueID); rbErr != nil {
+					return errors.Join(err, fmt.Errorf("rolling back issue %s: %w", issueID, rbErr))
+				}
+				created.rolledBack = true
+				return fmt.Errorf("%w; deleted issue %s again", err, issueID)
+			}
+			created.incomplete = false
 		}
 	}
 	return nil
 }
+
+// dueTemplate is a template with the occurrences a run creates issues for.
+type dueTemplate struct {
+	tmpl        issueTemplate
+	ts          templateSchedule
+	now         time.Time   // in the template's time zone
+	today       time.Time   // the template's day at now
+	occurrences []time.Time // oldest first; the last one may be today
+	windowStart time.Time   // start of the first day checked
+	windowEnd   time.Time   // end of today
+}
+
+// dueOccurrences returns the occurrences of tmpl that a run at cfg's now is
+// due to create issues for: today's, once its time of day has come, and if
+// lastRun is not zero, those on the days after the one lastRun fell on (or
+// from that day, if lastRun was before the time of day), up to
+// cfg.maxCatchUpDays back. It also returns the window of creation times in
+// which already created issues count against them.
+func dueOccurrences(tmpl issueTemplate, cfg config, lastRun time.Time) dueTemplate {
+	ts := parseTemplateSchedule(tmpl.description, cfg.calendars)
+	loc := ts.locationOr(cfg.location)
+	now := cfg.now(loc)
+	today := ts.today(now, cfg.location)
+
+	first := today
+	if !lastRun.IsZero() && ts.catchUp != catchUpSkip {
+		// A last run before the template's time of day left that day's
+		// occurrence for later.
+		first = ts.today(lastRun, cfg.location)
+		if ts.timeReached(lastRun, cfg.location) {
+			first = first.AddDate(0, 0, 1)
+		}
+		if earliest := today.AddDate(0, 0, -cfg.maxCatchUpDays); first.Before(earliest) {
+			first = earliest
+		}
+		// A -date before the last run has nothing to catch up on.
+		if first.After(today) {
+			first = today
+		}
+	}
+	var occurrences []time.Time
+	for d := first; d.Before(today); d = d.AddDate(0, 0, 1) {
+		if ts.matches(d) {
+			occurrences = append(occurrences, d)
+		}
+	}
+	if ts.matches(today) && ts.timeReached(now, cfg.location) {
+		occurrences = append(occurrences, today)
+	}
+
+	return dueTemplate{
+		tmpl:        tmpl,
+		ts:          ts,
+		now:         now,
+		today:       today,
+		occurrences: occurrences,
+		windowStart: time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc),
+		windowEnd:   ts.dayStart(now, cfg.location).AddDate(0, 0, 1),
+	}
+}
+
+// missed returns the occurrences before today.
+func (d dueTemplate) missed() []time.Time {
+	if n := len(d.occurrences); n > 0 && d.occurrences[n-1].Equal(d.today) {
+		return d.occurrences[:n-1]
+	}
+	return d.occurrences
+}
+
+// toCreate returns the days to create issues for, given that created issues
+// from the template were created in the window already. Those stand for the
+// earliest occurrences.
+func (d dueTemplate) toCreate(created int) []time.Time {
+	days := d.occurrences
+	if d.ts.catchUp == catchUpLatest && len(days) > 0 {
+		days = days[len(days)-1:]
+	}
+	if created >= len(days) {
+		return nil
+	}
+	return days[created:]
+}
+
+// issueDate returns when an issue for day should be dated: zero, for now, if
+// day is today, or the template's time of day on a day that has passed. Dating
+// issues for missed or backfilled days on those days keeps the already-created
+// check of later runs, which goes by creation time, working for them.
+func issueDate(ts templateSchedule, day time.Time, def *time.Location) time.Time {
+	if !day.Before(ts.today(time.Now(), def)) {
+		return time.Time{}
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, ts.locationOr(def)).Add(ts.timeOfDay)
+}
+
+// rollBack deletes an issue created by this run and its sub-issues, so that a
+// half set-up issue does not stop the next run from creating it again. It
+// runs even if ctx has been cancelled, as an interrupted run should clean up
+// after itself too.
+func rollBack(ctx context.Context, q q, issueID string) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollBackTimeout)
+	defer cancel()
+
+	fmt.Printf("  rolling back issue %s\n", issueID)
+	children, err := getChildIssues(ctx, q, issueID)
+	if err != nil {
+		return fmt.Errorf("fetching sub-issues: %w", err)
+	}
+	for _, child := range children {
+		if err := deleteIssue(ctx, q, child.id); err != nil {
+			return err
+		}
+	}
+	return deleteIssue(ctx, q, issueID)
+}
+
+const rollBackTimeout = time.Minute
+
+func formatDates(dates []time.Time) string {
+	formatted := make([]string, len(dates))
+	for i, d := range dates {
+		formatted[i] = d.Format("2006-01-02")
+	}
+	return strings.Join(formatted, ", ")
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+// fixedClock returns a config clock stopped at t.
+func fixedClock(t time.Time) func(*time.Location) time.Time {
+	return func(loc *time.Location) time.Time { return t.In(loc) }
+}
+
+func TestCreateFromDueTemplates_CreatesAndSetsUpSubIssues(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC) // Monday
+	fake.now = func() time.Time { return now }
+	cfg := config{location: time.UTC, clock: fixedClock(now)}
+
+	dueID := fake.addTemplate("team-1", "Weekly", "Recurrence: Mon", "1|REQ Prepare", "2|DEPS1 Run", "Notes")
+	notDueID := fake.addTemplate("team-1", "Friday", "Recurrence: Fri")
+	otherTeamID := fake.addTemplate("team-2", "Other team", "Recurrence: daily")
+
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", time.Time{}, nil))
+
+	created := fake.issuesFromTemplate(dueID)
+	assert.Equal(t, 1, len(created))
+	assert.Equal(t, 0, len(fake.issuesFromTemplate(notDueID)))
+	assert.Equal(t, 0, len(fake.issuesFromTemplate(otherTeamID)))
+
+	parentID := created[0].id
+	children, err := getChildIssues(context.Background(), q, parentID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Prepare", "Run", "Notes"}, []string{children[0].title, children[1].title, children[2].title})
+	assert.Equal(t, 2, len(fake.relations))
+
+	// A second run the same day finds the issue and creates nothing.
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", time.Time{}, nil))
+	assert.Equal(t, 1, len(fake.issuesFromTemplate(dueID)))
+}
+
+func TestCreateFromDueTemplates_DedupPaginates(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	fake.pageSize = 2
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	fake.now = func() time.Time { return now }
+	cfg := config{location: time.UTC, clock: fixedClock(now)}
+
+	var ids []string
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		ids = append(ids, fake.addTemplate("team-1", name, "Recurrence: daily"))
+	}
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", time.Time{}, nil))
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", time.Time{}, nil))
+	for _, id := range ids {
+		assert.Equal(t, 1, len(fake.issuesFromTemplate(id)))
+	}
+}
+
+func TestCreateFromDueTemplates_TimeOfDayAndTimezone(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	// Sunday 2026-03-01 21:00 UTC is Monday 08:00 in Sydney.
+	now := time.Date(2026, time.March, 1, 21, 0, 0, 0, time.UTC)
+	fake.now = func() time.Time { return now }
+	cfg := config{location: time.UTC, clock: fixedClock(now)}
+
+	id := fake.addTemplate("team-1", "Sydney", "Recurrence: Mon|Timezone: Australia/Sydney|Time: 09:00")
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", time.Time{}, nil))
+	assert.Equal(t, 0, len(fake.issuesFromTemplate(id)))
+
+	now = now.Add(time.Hour)
+	cfg.clock = fixedClock(now)
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", time.Time{}, nil))
+	assert.Equal(t, 1, len(fake.issuesFromTemplate(id)))
+}
+
+func TestCreateFromDueTemplates_CatchUp(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC) // Monday
+	fake.now = func() time.Time { return now }
+	cfg := config{location: time.UTC, clock: fixedClock(now), maxCatchUpDays: 7}
+	lastRun := time.Date(2026, time.February, 27, 9, 0, 0, 0, time.UTC) // Friday
+
+	allID := fake.addTemplate("team-1", "All", "Recurrence: daily|CatchUp: all")
+	latestID := fake.addTemplate("team-1", "Latest", "Recurrence: Sat")
+	skipID := fake.addTemplate("team-1", "Skip", "Recurrence: Sun|CatchUp: skip")
+
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", lastRun, nil))
+	assert.Equal(t, 3, len(fake.issuesFromTemplate(allID))) // Sat, Sun, Mon
+	assert.Equal(t, 1, len(fake.issuesFromTemplate(latestID)))
+	assert.Equal(t, 0, len(fake.issuesFromTemplate(skipID)))
+
+	// Rerunning after a failure to record the run doesn't create them again.
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", lastRun, nil))
+	assert.Equal(t, 3, len(fake.issuesFromTemplate(allID)))
+	assert.Equal(t, 1, len(fake.issuesFromTemplate(latestID)))
+}
+
+func TestCreateFromDueTemplates_CatchUpBeforeTimeOfDay(t *testing.T) {
+	now := time.Date(2026, time.March, 2, 9, 30, 0, 0, time.UTC) // Monday
+	for _, tc := range []struct {
+		name    string
+		lastRun time.Time
+		want    int
+	}{
+		{"last run before the time", time.Date(2026, time.February, 27, 8, 0, 0, 0, time.UTC), 4}, // Fri to Mon
+		{"last run after the time", time.Date(2026, time.February, 27, 10, 0, 0, 0, time.UTC), 3}, // Sat to Mon
+		{"last run earlier today", time.Date(2026, time.March, 2, 8, 0, 0, 0, time.UTC), 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			fake, q := newFakeLinear(t)
+			fake.now = func() time.Time { return now }
+			cfg := config{location: time.UTC, clock: fixedClock(now), maxCatchUpDays: 7}
+			id := fake.addTemplate("team-1", "All", "Recurrence: daily|Time: 09:00|CatchUp: all")
+			assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", tc.lastRun, nil))
+			assert.Equal(t, tc.want, len(fake.issuesFromTemplate(id)))
+		})
+	}
+}
+
+func TestCreateFromDueTemplates_CatchUpLimit(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	fake.now = func() time.Time { return now }
+	cfg := config{location: time.UTC, clock: fixedClock(now), maxCatchUpDays: 2}
+	lastRun := time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+	id := fake.addTemplate("team-1", "All", "Recurrence: daily|CatchUp: all")
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", lastRun, nil))
+	assert.Equal(t, 3, len(fake.issuesFromTemplate(id)))
+}
+
+func TestCreateFromDueTemplates_Backfill(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	id := fake.addTemplate("team-1", "Daily", "Recurrence: daily|Time: 09:00")
+	today := civilDate(time.Now().UTC())
+	backfill := config{location: time.UTC, clock: dateClock(today.AddDate(0, 0, -3))}
+
+	// The backfilled issue is dated on its day, so backfilling again finds it.
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, backfill, "team-1", time.Time{}, nil))
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, backfill, "team-1", time.Time{}, nil))
+	created := fake.issuesFromTemplate(id)
+	assert.Equal(t, 1, len(created))
+	assert.Equal(t, today.AddDate(0, 0, -3).Add(9*time.Hour), created[0].createdAt.UTC())
+
+	// And it does not stand in for today's issue.
+	now := config{location: time.UTC, clock: dateClock(today)}
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, now, "team-1", time.Time{}, nil))
+	assert.Equal(t, 2, len(fake.issuesFromTemplate(id)))
+}
+
+func TestRunTeams_BackfillWithState(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	id := fake.addTemplate("team-1", "Daily", "Recurrence: daily|Time: 09:00")
+	today := civilDate(time.Now().UTC())
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	lastRun := time.Now().Add(-time.Minute)
+	assert.NoError(t, runState{LastRun: map[string]time.Time{"team-1": lastRun}}.save(statePath))
+	// The cron job's flags, with a -date before its last run.
+	cfg := config{location: time.UTC, clock: dateClock(today.AddDate(0, 0, -3)), statePath: statePath, maxCatchUpDays: 7, endpoint: q.endpoint, client: q.client}
+
+	assert.Equal(t, 0, runTeams(context.Background(), q.token, cfg, []string{"Test Team"}))
+	assert.Equal(t, 0, runTeams(context.Background(), q.token, cfg, []string{"Test Team"}))
+	assert.Equal(t, 1, len(fake.issuesFromTemplate(id)))
+	state, err := loadRunState(statePath)
+	assert.NoError(t, err)
+	assert.True(t, state.LastRun["team-1"].Equal(lastRun), "backfills leave the state alone")
+}
+
+func TestRunTeams_RefusesFutureDate(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	id := fake.addTemplate("team-1", "Daily", "Recurrence: daily")
+	cfg := config{location: time.UTC, clock: dateClock(time.Now().AddDate(0, 0, 2)), endpoint: q.endpoint, client: q.client}
+	assert.Equal(t, 2, runTeams(context.Background(), q.token, cfg, []string{"Test Team"}))
+	assert.Equal(t, 0, len(fake.issuesFromTemplate(id)))
+}
+
+func TestCreateFromDueTemplates_DryRun(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	q.dryRun = newDryRun()
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	cfg := config{location: time.UTC, clock: fixedClock(now)}
+
+	id := fake.addTemplate("team-1", "Weekly", "Recurrence: Mon", "1|REQ Prepare", "2|DEPS1 Run")
+	assert.NoError(t, createFromDueTemplates(context.Background(), q, cfg, "team-1", time.Time{}, nil))
+	assert.Equal(t, 0, len(fake.issuesFromTemplate(id)))
+	assert.Equal(t, 0, len(fake.relations))
+}
+
+func TestCreateFromDueTemplates_ReportsWhenCancelled(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	fake.now = func() time.Time { return now }
+	cfg := config{location: time.UTC, clock: fixedClock(now)}
+	fake.addTemplate("team-1", "Daily", "Recurrence: daily", "1|REQ Prepare")
+	fake.addTemplate("team-1", "Also daily", "Recurrence: daily")
+
+	// Stop the run once the first issue has been created.
+	ctx, cancel := context.WithCancel(context.Background())
+	fake.onRequest = func(field string) {
+		if field == "issue" {
+			cancel()
+		}
+	}
+
+	report := &runReport{}
+	err := createFromDueTemplates(ctx, q, cfg, "team-1", time.Time{}, report)
+	assert.IsError(t, err, context.Canceled)
+	assert.Equal(t, 1, len(report.created))
+	assert.Equal(t, "Daily", report.created[0].template)
+	assert.True(t, report.created[0].incomplete)
+	assert.Equal(t, 2, len(fake.issues)) // the issue and its sub-issue
+}
+
+func TestCreateFromDueTemplates_FailsWhenDedupQueryFails(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	fake.now = func() time.Time { return now }
+	cfg := config{location: time.UTC, clock: fixedClock(now)}
+	id := fake.addTemplate("team-1", "Daily", "Recurrence: daily")
+
+	// The templates query succeeds; the query for already created issues fails.
+	fake.onRequest = func(field string) {
+		if field == "issues" {
+			fake.failures = []fakeFailure{{status: http.StatusOK, code: "FORBIDDEN"}}
+		}
+	}
+	err := createFromDueTemplates(context.Background(), q, cfg, "team-1", time.Time{}, nil)
+	var apiErr *apiError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "IssuesCreatedBetween", apiErr.operation)
+	assert.Equal(t, 0, len(fake.issuesFromTemplate(id)))
+}
+
+func TestCreateFromDueTemplates_RollsBackFailedSetup(t *testing.T) {
+	for _, rollback := range []bool{false, true} {
+		fake, q := newFakeLinear(t)
+		now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+		fake.now = func() time.Time { return now }
+		cfg := config{location: time.UTC, clock: fixedClock(now), rollback: rollback}
+		id := fake.addTemplate("team-1", "Daily", "Recurrence: daily", "1|REQ Prepare", "2|DEPS1 Run")
+
+		// The first relation is created, the second one fails.
+		relations := 0
+		fake.onRequest = func(field string) {
+			if field == "issueRelationCreate" {
+				if relations++; relations == 2 {
+					fake.failures = []fakeFailure{{status: http.StatusBadRequest, code: "INVALID_INPUT"}}
+				}
+			}
+		}
+
+		report := &runReport{}
+		err := createFromDueTemplates(context.Background(), q, cfg, "team-1", time.Time{}, report)
+		assert.Error(t, err)
+		assert.Equal(t, 1, len(report.created))
+		assert.Equal(t, rollback, report.created[0].rolledBack)
+		if rollback {
+			assert.Contains(t, err.Error(), "deleted issue")
+			assert.Equal(t, 0, len(fake.issues))
+			assert.Equal(t, 0, len(fake.relations))
+			assert.Equal(t, 0, len(fake.issuesFromTemplate(id)))
+		} else {
+			assert.Equal(t, 3, len(fake.issues))
+			assert.Equal(t, 1, len(fake.relations))
+		}
+	}
+}
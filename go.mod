@@ -2,7 +2,10 @@ module linear-future
 
 go 1.23.4
 
-require github.com/alecthomas/assert/v2 v2.11.0
+require (
+	github.com/alecthomas/assert/v2 v2.11.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/alecthomas/repr v0.4.0 // indirect
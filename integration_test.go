@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -13,25 +14,24 @@ import (
 
 const testMarker = "[LFIT]"
 
-func skipUnlessIntegration(t *testing.T) {
+// integrationQ returns a client for a fake Linear server, or for the real API
+// if LINEAR_FUTURE_INTEGRATION_TESTS is set.
+func integrationQ(t *testing.T) q {
 	t.Helper()
 	if os.Getenv("LINEAR_FUTURE_INTEGRATION_TESTS") == "" {
-		t.Skip("set LINEAR_FUTURE_INTEGRATION_TESTS=1 to run integration tests")
+		_, q := newFakeLinear(t)
+		return q
 	}
-}
-
-func integrationQ(t *testing.T) q {
-	t.Helper()
 	token := os.Getenv("LINEAR_API_KEY")
 	assert.NotEqual(t, "", token, "LINEAR_API_KEY must be set for integration tests")
-	return q{token}
+	return q{token: token}
 }
 
 // --- test helpers ---
 
 func testGetTeamID(t *testing.T, q q) string {
 	t.Helper()
-	body, err := q.do(`query { teams { nodes { id } } }`, nil)
+	body, err := q.do(context.Background(), `query { teams { nodes { id } } }`, nil)
 	assert.NoError(t, err)
 	var resp struct {
 		Data struct {
@@ -77,7 +77,7 @@ func testCreateTemplateWithSubIssues(t *testing.T, q q, teamID, name, descriptio
 		"teamId":       teamID,
 		"templateData": json.RawMessage(tdJSON),
 	}
-	body, err := q.do(mutation, map[string]any{"input": input})
+	body, err := q.do(context.Background(), mutation, map[string]any{"input": input})
 	assert.NoError(t, err)
 	var resp struct {
 		Data struct {
@@ -99,7 +99,7 @@ func testDeleteTemplate(t *testing.T, q q, id string) {
 	mutation := `mutation ($id: String!) {
 		templateDelete(id: $id) { success }
 	}`
-	body, err := q.do(mutation, map[string]any{"id": id})
+	body, err := q.do(context.Background(), mutation, map[string]any{"id": id})
 	if err != nil {
 		t.Logf("delete template %s: %v", id, err)
 		return
@@ -123,7 +123,7 @@ func testCreateIssueFromTemplate(t *testing.T, q q, templateID, teamID string) s
 			issue { id }
 		}
 	}`
-	body, err := q.do(mutation, map[string]any{"templateId": templateID, "teamId": teamID})
+	body, err := q.do(context.Background(), mutation, map[string]any{"templateId": templateID, "teamId": teamID})
 	assert.NoError(t, err)
 	var resp struct {
 		Data struct {
@@ -145,7 +145,7 @@ func testDeleteIssue(t *testing.T, q q, id string) {
 	mutation := `mutation ($id: String!) {
 		issueDelete(id: $id) { success }
 	}`
-	body, err := q.do(mutation, map[string]any{"id": id})
+	body, err := q.do(context.Background(), mutation, map[string]any{"id": id})
 	if err != nil {
 		t.Logf("delete issue %s: %v", id, err)
 		return
@@ -174,7 +174,7 @@ func testCreateChildIssue(t *testing.T, q q, teamID, parentID, title string) str
 		"title":    title,
 		"parentId": parentID,
 	}
-	body, err := q.do(mutation, map[string]any{"input": input})
+	body, err := q.do(context.Background(), mutation, map[string]any{"input": input})
 	assert.NoError(t, err)
 	var resp struct {
 		Data struct {
@@ -215,7 +215,7 @@ func testGetIssueRelations(t *testing.T, q q, issueID string) []testRelation {
 			}
 		}
 	}`
-	body, err := q.do(query, map[string]any{"id": issueID})
+	body, err := q.do(context.Background(), query, map[string]any{"id": issueID})
 	assert.NoError(t, err)
 	var resp struct {
 		Data struct {
@@ -250,7 +250,7 @@ func testGetIssueRelations(t *testing.T, q q, issueID string) []testRelation {
 func testGetIssueTitle(t *testing.T, q q, issueID string) string {
 	t.Helper()
 	query := `query ($id: String!) { issue(id: $id) { title } }`
-	body, err := q.do(query, map[string]any{"id": issueID})
+	body, err := q.do(context.Background(), query, map[string]any{"id": issueID})
 	assert.NoError(t, err)
 	var resp struct {
 		Data struct {
@@ -265,7 +265,7 @@ func testGetIssueTitle(t *testing.T, q q, issueID string) string {
 func testCleanupMarked(t *testing.T, q q, teamID string) {
 	t.Helper()
 
-	issues, err := searchTeamIssues(q, teamID, testMarker)
+	issues, err := searchTeamIssues(context.Background(), q, teamID, testMarker)
 	if err != nil {
 		t.Logf("cleanup: list issues: %v", err)
 	} else {
@@ -274,7 +274,7 @@ func testCleanupMarked(t *testing.T, q q, teamID string) {
 		}
 	}
 
-	templates, err := getTemplates(q)
+	templates, err := getTemplates(context.Background(), q)
 	if err != nil {
 		t.Logf("cleanup: list templates: %v", err)
 	} else {
@@ -300,7 +300,6 @@ func findTemplate(t *testing.T, templates []issueTemplate, id string) issueTempl
 // --- integration tests ---
 
 func TestIntegration(t *testing.T) {
-	skipUnlessIntegration(t)
 	q := integrationQ(t)
 	teamID := testGetTeamID(t, q)
 
@@ -312,7 +311,7 @@ func TestIntegration(t *testing.T) {
 		name := testMarker + " roundtrip"
 		tmplID := testCreateTemplate(t, q, teamID, name, description)
 
-		templates, err := getTemplates(q)
+		templates, err := getTemplates(context.Background(), q)
 		assert.NoError(t, err)
 
 		found := findTemplate(t, templates, tmplID)
@@ -324,7 +323,7 @@ func TestIntegration(t *testing.T) {
 	t.Run("RecurrenceMatching", func(t *testing.T) {
 		tmplID := testCreateTemplate(t, q, teamID, testMarker+" recurrence", "Recurrence: daily")
 
-		templates, err := getTemplates(q)
+		templates, err := getTemplates(context.Background(), q)
 		assert.NoError(t, err)
 
 		found := findTemplate(t, templates, tmplID)
@@ -334,7 +333,7 @@ func TestIntegration(t *testing.T) {
 
 		tmplID2 := testCreateTemplate(t, q, teamID, testMarker+" no-recurrence", "Just a description")
 
-		templates2, err := getTemplates(q)
+		templates2, err := getTemplates(context.Background(), q)
 		assert.NoError(t, err)
 
 		found2 := findTemplate(t, templates2, tmplID2)
@@ -344,7 +343,7 @@ func TestIntegration(t *testing.T) {
 	t.Run("WeekdayRecurrence", func(t *testing.T) {
 		tmplID := testCreateTemplate(t, q, teamID, testMarker+" weekday", "Recurrence: Wed")
 
-		templates, err := getTemplates(q)
+		templates, err := getTemplates(context.Background(), q)
 		assert.NoError(t, err)
 
 		found := findTemplate(t, templates, tmplID)
@@ -362,9 +361,9 @@ func TestIntegration(t *testing.T) {
 		testCreateIssueFromTemplate(t, q, tmplID, teamID)
 
 		today := time.Now().UTC().Truncate(24 * time.Hour)
-		created, err := getTemplateCreatedIssuesForDay(q, teamID, today)
+		created, err := getTemplateCreatedIssueCounts(context.Background(), q, teamID, today, today.AddDate(0, 0, 1))
 		assert.NoError(t, err)
-		assert.True(t, created[tmplID])
+		assert.True(t, created[tmplID] > 0)
 	})
 
 	t.Run("CreateRecurringIssuesIdempotent", func(t *testing.T) {
@@ -374,12 +373,12 @@ func TestIntegration(t *testing.T) {
 
 		testCreateIssueFromTemplate(t, q, tmplID, teamID)
 
-		created, err := getTemplateCreatedIssuesForDay(q, teamID, today)
+		created, err := getTemplateCreatedIssueCounts(context.Background(), q, teamID, today, today.AddDate(0, 0, 1))
 		assert.NoError(t, err)
-		assert.True(t, created[tmplID])
+		assert.True(t, created[tmplID] > 0)
 
 		// Simulate what createFromDueTemplates does: skip if already created.
-		templates, err := getTemplates(q)
+		templates, err := getTemplates(context.Background(), q)
 		assert.NoError(t, err)
 		var dueTemplates []issueTemplate
 		for _, tmpl := range templates {
@@ -392,7 +391,7 @@ func TestIntegration(t *testing.T) {
 		for _, tmpl := range dueTemplates {
 			if tmpl.id == tmplID {
 				foundOurs = true
-				assert.True(t, created[tmpl.id])
+				assert.True(t, created[tmpl.id] > 0)
 			}
 		}
 		assert.True(t, foundOurs, "our daily template should be in the due list")
@@ -401,7 +400,7 @@ func TestIntegration(t *testing.T) {
 	t.Run("MonthDayRecurrence", func(t *testing.T) {
 		tmplID := testCreateTemplate(t, q, teamID, testMarker+" monthday", "Recurrence: Mar 15")
 
-		templates, err := getTemplates(q)
+		templates, err := getTemplates(context.Background(), q)
 		assert.NoError(t, err)
 
 		found := findTemplate(t, templates, tmplID)
@@ -418,7 +417,7 @@ func TestIntegration(t *testing.T) {
 	t.Run("MultipleRecurrenceLines", func(t *testing.T) {
 		tmplID := testCreateTemplate(t, q, teamID, testMarker+" multi", "Recurrence: Mon\nRecurrence: 15\nOther info")
 
-		templates, err := getTemplates(q)
+		templates, err := getTemplates(context.Background(), q)
 		assert.NoError(t, err)
 
 		found := findTemplate(t, templates, tmplID)
@@ -436,7 +435,7 @@ func TestIntegration(t *testing.T) {
 		description := "Recurrence: daily\nExtra info"
 		tmplID := testCreateTemplate(t, q, teamID, testMarker+" list", description)
 
-		templates, err := getTemplates(q)
+		templates, err := getTemplates(context.Background(), q)
 		assert.NoError(t, err)
 
 		found := findTemplate(t, templates, tmplID)
@@ -452,7 +451,7 @@ func TestIntegration(t *testing.T) {
 		child2ID := testCreateChildIssue(t, q, teamID, parentID, "2|DEPS1 "+testMarker+" Second task")
 		testCreateChildIssue(t, q, teamID, parentID, testMarker+" No prefix task")
 
-		assert.NoError(t, setupSubIssueDependencies(q, parentID))
+		assert.NoError(t, setupSubIssueDependencies(context.Background(), q, parentID))
 
 		// Verify titles were stripped.
 		assert.Equal(t, testMarker+" First task", testGetIssueTitle(t, q, child1ID))
@@ -488,7 +487,7 @@ func TestIntegration(t *testing.T) {
 		}
 		tmplID := testCreateTemplateWithSubIssues(t, q, teamID, name, "Recurrence: daily", subTitles)
 
-		templates, err := getTemplates(q)
+		templates, err := getTemplates(context.Background(), q)
 		assert.NoError(t, err)
 
 		found := findTemplate(t, templates, tmplID)
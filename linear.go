@@ -2,18 +2,30 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"regexp"
+	"strings"
 	"time"
 )
 
+const defaultEndpoint = "https://api.linear.app/graphql"
+
 type q struct {
-	token string
+	token    string
+	endpoint string        // GraphQL endpoint; defaultEndpoint if empty
+	client   *http.Client  // http.DefaultClient if nil
+	retry    retryPolicy   // zero value makes a single attempt
+	timeout  time.Duration // per attempt; no limit if zero
+	dryRun   *dryRun       // if set, mutations are printed instead of made
 }
 
-func (q q) do(query string, variables map[string]any) ([]byte, error) {
+func (q q) do(ctx context.Context, query string, variables map[string]any) ([]byte, error) {
 
 	reqBody, err := json.Marshal(struct {
 		Query     string         `json:"query"`
@@ -23,63 +35,251 @@ func (q q) do(query string, variables map[string]any) ([]byte, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", "https://api.linear.app/graphql", bytes.NewBuffer(reqBody))
+	isMutation := strings.HasPrefix(strings.TrimSpace(query), "mutation")
+	op := operationName(query)
+	for attempt := 1; ; attempt++ {
+		body, err := q.doOnce(ctx, op, reqBody)
+		var retryable *retryableError
+		if err == nil || !errors.As(err, &retryable) {
+			return body, err
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if attempt >= q.retry.maxAttempts || isMutation && !retryable.rateLimited {
+			return nil, err
+		}
+		delay := q.retry.backoff(attempt, retryable.wait)
+		if delay > q.retry.maxDelay {
+			return nil, fmt.Errorf("%w (retry would need to wait %s)", err, delay)
+		}
+		fmt.Fprintf(os.Stderr, "request failed, retrying in %s: %v\n", delay.Round(time.Millisecond), err)
+		if err := q.retry.sleepFor(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// doOnce makes a single request. Failures worth retrying, including running
+// out of q.timeout, are *retryableError.
+func (q q) doOnce(ctx context.Context, op string, reqBody []byte) ([]byte, error) {
+	endpoint := q.endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	reqCtx := ctx
+	if q.timeout > 0 {
+		var cancel context.CancelFunc
+		reqCtx, cancel = context.WithTimeout(ctx, q.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(reqCtx, "POST", endpoint, bytes.NewBuffer(reqBody))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", q.token) // NB! no "bearer"
 
-	resp, err := http.DefaultClient.Do(req)
+	client := q.client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, &retryableError{err: err}
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, &retryableError{err: err}
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("non-200 response: %d, body: %s", resp.StatusCode, string(body))
+	if err := classifyResponse(op, resp, body); err != nil {
+		return nil, err
 	}
 
+	// Out of budget: wait for the reset instead of failing the next request.
+	if wait := rateLimitWait(resp.Header, time.Now()); wait > 0 && wait <= q.retry.maxDelay {
+		fmt.Fprintf(os.Stderr, "rate limit reached, waiting %s\n", wait.Round(time.Millisecond))
+		if err := q.retry.sleepFor(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
 	return body, nil
 }
 
-func getTeamID(q q, teamName string) (string, error) {
+// query runs a GraphQL operation and decodes the response's data into out.
+// GraphQL errors in the response are returned as an *apiError; any partial
+// data that came with them is still decoded into out.
+func (q q) query(ctx context.Context, query string, variables map[string]any, out any) error {
+	body, err := q.do(ctx, query, variables)
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("%s: decoding response: %w", operationName(query), err)
+	}
+	if len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, out); err != nil {
+			return fmt.Errorf("%s: decoding response: %w", operationName(query), err)
+		}
+	}
+	if apiErr := parseAPIError(operationName(query), http.StatusOK, body); apiErr != nil {
+		return apiErr
+	}
+	return nil
+}
+
+// graphQLError is an entry of the errors array of a GraphQL response.
+type graphQLError struct {
+	Message    string `json:"message"`
+	Path       []any  `json:"path"` // field names and list indices
+	Extensions struct {
+		Code string `json:"code"` // e.g. INVALID_INPUT or RATELIMITED
+	} `json:"extensions"`
+}
+
+func (e graphQLError) String() string {
+	s := e.Message
+	if len(e.Path) > 0 {
+		path := make([]string, len(e.Path))
+		for i, p := range e.Path {
+			path[i] = fmt.Sprint(p)
+		}
+		s += " at " + strings.Join(path, ".")
+	}
+	if e.Extensions.Code != "" {
+		s += " (" + e.Extensions.Code + ")"
+	}
+	return s
+}
+
+// apiError is a response from Linear that reported GraphQL errors. Use
+// errors.As to inspect it.
+type apiError struct {
+	operation string // operation name from the query, e.g. "GetTeam"
+	status    int    // HTTP status code
+	errors    []graphQLError
+	data      json.RawMessage // partial data returned with the errors, if any
+}
+
+func (e *apiError) Error() string {
+	msg := fmt.Sprintf("%s failed: %s", e.operation, e.errors[0])
+	if len(e.errors) > 1 {
+		msg += fmt.Sprintf(" (and %d more)", len(e.errors)-1)
+	}
+	return msg
+}
+
+// code returns the extensions.code of the first error.
+func (e *apiError) code() string {
+	if e == nil {
+		return ""
+	}
+	return e.errors[0].Extensions.Code
+}
+
+// parseAPIError returns the GraphQL errors in a response body as an
+// *apiError, or nil if it has none.
+func parseAPIError(op string, status int, body []byte) *apiError {
+	var resp struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	if json.Unmarshal(body, &resp) != nil || len(resp.Errors) == 0 {
+		return nil
+	}
+	return &apiError{operation: op, status: status, errors: resp.Errors, data: resp.Data}
+}
+
+var operationNameRx = regexp.MustCompile(`^\s*(?:query|mutation)\s+(\w+)`)
+
+// operationName returns the name of the operation in a GraphQL document, or
+// "GraphQL request" for anonymous ones.
+func operationName(query string) string {
+	if m := operationNameRx.FindStringSubmatch(query); m != nil {
+		return m[1]
+	}
+	return "GraphQL request"
+}
+
+func getTeamID(ctx context.Context, q q, teamName string) (string, error) {
 	query := `query GetTeam($teamName: String!) {
 		teams(filter: {name: {eq: $teamName}}) {
 			nodes { id }
 		}
 	}`
-	body, err := q.do(query, map[string]any{"teamName": teamName})
-	if err != nil {
+	var data struct {
+		Teams struct {
+			Nodes []struct {
+				ID string
+			}
+		}
+	}
+	if err := q.query(ctx, query, map[string]any{"teamName": teamName}, &data); err != nil {
 		return "", err
 	}
+	if len(data.Teams.Nodes) == 0 {
+		return "", fmt.Errorf("failed to resolve team name %q to an ID: no team found", teamName)
+	}
 
-	var resp struct {
-		Data struct {
+	return data.Teams.Nodes[0].ID, nil
+}
+
+type team struct {
+	id   string
+	name string
+}
+
+// getTeams returns all teams in the workspace.
+func getTeams(ctx context.Context, q q) ([]team, error) {
+	query := `query Teams($after: String) {
+		teams(first: 50, after: $after) {
+			nodes { id name }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`
+
+	var out []team
+	cursor := ""
+	for {
+		vars := map[string]any{}
+		if cursor != "" {
+			vars["after"] = cursor
+		}
+		var data struct {
 			Teams struct {
 				Nodes []struct {
-					ID string
+					ID   string
+					Name string
+				}
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
 				}
 			}
 		}
-	}
-	if err := json.Unmarshal(body, &resp); err != nil {
-		return "", err
-	}
-	if len(resp.Data.Teams.Nodes) == 0 {
-		return "", fmt.Errorf("failed to resolve team name %q to an ID: no team found", teamName)
-	}
+		if err := q.query(ctx, query, vars, &data); err != nil {
+			return nil, err
+		}
+
+		for _, n := range data.Teams.Nodes {
+			out = append(out, team{id: n.ID, name: n.Name})
+		}
 
-	return resp.Data.Teams.Nodes[0].ID, nil
+		if !data.Teams.PageInfo.HasNextPage {
+			return out, nil
+		}
+		cursor = data.Teams.PageInfo.EndCursor
+	}
 }
 
 // searchTeamIssues searches for issues in a team whose title contains the given string.
-func searchTeamIssues(q q, teamID, titleContains string) ([]subIssue, error) {
+func searchTeamIssues(ctx context.Context, q q, teamID, titleContains string) ([]subIssue, error) {
 	query := `query SearchIssues($teamID: ID!, $title: StringComparator!, $after: String) {
 		issues(filter: { team: {id: {eq: $teamID}}, title: $title }, first: 50, after: $after) {
 			nodes { id title }
@@ -97,41 +297,38 @@ func searchTeamIssues(q q, teamID, titleContains string) ([]subIssue, error) {
 		if cursor != "" {
 			vars["after"] = cursor
 		}
-		body, err := q.do(query, vars)
-		if err != nil {
-			return nil, err
-		}
-
-		var resp struct {
-			Data struct {
-				Issues struct {
-					Nodes []struct {
-						ID    string
-						Title string
-					}
-					PageInfo struct {
-						HasNextPage bool
-						EndCursor   string
-					}
+		var data struct {
+			Issues struct {
+				Nodes []struct {
+					ID    string
+					Title string
+				}
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
 				}
 			}
 		}
-		if err := json.Unmarshal(body, &resp); err != nil {
+		if err := q.query(ctx, query, vars, &data); err != nil {
 			return nil, err
 		}
 
-		for _, n := range resp.Data.Issues.Nodes {
+		for _, n := range data.Issues.Nodes {
 			out = append(out, subIssue{id: n.ID, title: n.Title})
 		}
 
-		if !resp.Data.Issues.PageInfo.HasNextPage {
+		if !data.Issues.PageInfo.HasNextPage {
 			return out, nil
 		}
-		cursor = resp.Data.Issues.PageInfo.EndCursor
+		cursor = data.Issues.PageInfo.EndCursor
 	}
 }
 
-func updateTitle(q q, issueID, newTitle string) error {
+func updateTitle(ctx context.Context, q q, issueID, newTitle string) error {
+	if q.dryRun != nil {
+		q.dryRun.updateTitle(issueID, newTitle)
+		return nil
+	}
 	mutation := `
 	mutation IssueUpdate($id: String!, $title: String!) {
 		issueUpdate(id: $id, input: {title: $title}) {
@@ -142,22 +339,15 @@ func updateTitle(q q, issueID, newTitle string) error {
 		"id":    issueID,
 		"title": newTitle,
 	}
-	body, err := q.do(mutation, variables)
-	if err != nil {
-		return err
-	}
-
-	var resp struct {
-		Data struct {
-			IssueUpdate struct {
-				Success bool `json:"success"`
-			} `json:"issueUpdate"`
-		} `json:"data"`
+	var data struct {
+		IssueUpdate struct {
+			Success bool `json:"success"`
+		} `json:"issueUpdate"`
 	}
-	if err := json.Unmarshal(body, &resp); err != nil {
+	if err := q.query(ctx, mutation, variables, &data); err != nil {
 		return err
 	}
-	if !resp.Data.IssueUpdate.Success {
+	if !data.IssueUpdate.Success {
 		return fmt.Errorf("failed to update issue %s", issueID)
 	}
 	return nil
@@ -172,7 +362,7 @@ type issueTemplate struct {
 	subIssueTitles []string
 }
 
-func getTemplates(q q) ([]issueTemplate, error) {
+func getTemplates(ctx context.Context, q q) ([]issueTemplate, error) {
 	query := `query Templates {
 		templates {
 			id
@@ -185,36 +375,23 @@ func getTemplates(q q) ([]issueTemplate, error) {
 		}
 	}`
 
-	body, err := q.do(query, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var resp struct {
-		Data struct {
-			Templates []struct {
-				ID           string
-				Name         string
-				Description  string
-				TemplateData json.RawMessage
-				Team         struct {
-					ID string
-				}
+	var data struct {
+		Templates []struct {
+			ID           string
+			Name         string
+			Description  string
+			TemplateData json.RawMessage
+			Team         struct {
+				ID string
 			}
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
+		}
 	}
-	if err := json.Unmarshal(body, &resp); err != nil {
+	if err := q.query(ctx, query, nil, &data); err != nil {
 		return nil, err
 	}
-	if len(resp.Errors) > 0 {
-		return nil, fmt.Errorf("templates query failed: %s", resp.Errors[0].Message)
-	}
 
-	templates := make([]issueTemplate, 0, len(resp.Data.Templates))
-	for _, t := range resp.Data.Templates {
+	templates := make([]issueTemplate, 0, len(data.Templates))
+	for _, t := range data.Templates {
 		tmpl := issueTemplate{
 			id:          t.ID,
 			name:        t.Name,
@@ -224,6 +401,9 @@ func getTemplates(q q) ([]issueTemplate, error) {
 		tmpl.issueTitle, tmpl.subIssueTitles = parseTemplateData(t.TemplateData)
 		templates = append(templates, tmpl)
 	}
+	if q.dryRun != nil {
+		q.dryRun.recordTemplates(templates)
+	}
 	return templates, nil
 }
 
@@ -252,8 +432,10 @@ func parseTemplateData(data json.RawMessage) (string, []string) {
 	return td.Title, titles
 }
 
-func getTemplateCreatedIssuesForDay(q q, teamID string, dayStart time.Time) (map[string]bool, error) {
-	query := `query IssuesCreatedToday($teamID: ID!, $start: DateTimeOrDuration!, $end: DateTimeOrDuration!, $after: String) {
+// getTemplateCreatedIssueCounts returns how many issues created in [start, end)
+// were created from each template, by template ID.
+func getTemplateCreatedIssueCounts(ctx context.Context, q q, teamID string, start, end time.Time) (map[string]int, error) {
+	query := `query IssuesCreatedBetween($teamID: ID!, $start: DateTimeOrDuration!, $end: DateTimeOrDuration!, $after: String) {
 		issues(filter: { team: {id: {eq: $teamID}}, createdAt: { gte: $start, lt: $end } }, first: 50, after: $after) {
 			nodes {
 				lastAppliedTemplate { id }
@@ -265,60 +447,58 @@ func getTemplateCreatedIssuesForDay(q q, teamID string, dayStart time.Time) (map
 		}
 	}`
 
-	dayEnd := dayStart.Add(24 * time.Hour)
-	created := make(map[string]bool)
+	counts := make(map[string]int)
 	cursor := ""
 	for {
 		vars := map[string]any{
 			"teamID": teamID,
-			"start":  dayStart.Format(time.RFC3339),
-			"end":    dayEnd.Format(time.RFC3339),
+			"start":  start.Format(time.RFC3339),
+			"end":    end.Format(time.RFC3339),
 		}
 		if cursor != "" {
 			vars["after"] = cursor
 		}
 
-		body, err := q.do(query, vars)
-		if err != nil {
-			return nil, err
-		}
-
-		var resp struct {
-			Data struct {
-				Issues struct {
-					Nodes []struct {
-						LastAppliedTemplate *struct {
-							ID string
-						}
-					}
-					PageInfo struct {
-						HasNextPage bool
-						EndCursor   string
+		var data struct {
+			Issues struct {
+				Nodes []struct {
+					LastAppliedTemplate *struct {
+						ID string
 					}
 				}
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   string
+				}
 			}
 		}
-		if err := json.Unmarshal(body, &resp); err != nil {
+		if err := q.query(ctx, query, vars, &data); err != nil {
 			return nil, err
 		}
 
-		for _, n := range resp.Data.Issues.Nodes {
+		for _, n := range data.Issues.Nodes {
 			if n.LastAppliedTemplate != nil && n.LastAppliedTemplate.ID != "" {
-				created[n.LastAppliedTemplate.ID] = true
+				counts[n.LastAppliedTemplate.ID]++
 			}
 		}
 
-		if !resp.Data.Issues.PageInfo.HasNextPage {
-			return created, nil
+		if !data.Issues.PageInfo.HasNextPage {
+			return counts, nil
 		}
-		cursor = resp.Data.Issues.PageInfo.EndCursor
+		cursor = data.Issues.PageInfo.EndCursor
 	}
 }
 
-func createIssueFromTemplate(q q, templateID, teamID string) (string, error) {
+// createIssueFromTemplate creates an issue from a template and returns its ID.
+// If createdAt is not zero, the issue is dated then instead of now; it must be
+// in the past.
+func createIssueFromTemplate(ctx context.Context, q q, templateID, teamID string, createdAt time.Time) (string, error) {
+	if q.dryRun != nil {
+		return q.dryRun.createIssueFromTemplate(templateID, teamID, createdAt), nil
+	}
 	mutation := `
-	mutation IssueCreateFromTemplate($templateId: String!, $teamId: String!) {
-		issueCreate(input: {templateId: $templateId, teamId: $teamId}) {
+	mutation IssueCreateFromTemplate($templateId: String!, $teamId: String!, $createdAt: DateTime) {
+		issueCreate(input: {templateId: $templateId, teamId: $teamId, createdAt: $createdAt}) {
 			success
 			issue {
 				id
@@ -329,50 +509,75 @@ func createIssueFromTemplate(q q, templateID, teamID string) (string, error) {
 		"templateId": templateID,
 		"teamId":     teamID,
 	}
-	body, err := q.do(mutation, variables)
-	if err != nil {
-		return "", err
+	if !createdAt.IsZero() {
+		variables["createdAt"] = createdAt.Format(time.RFC3339)
 	}
-
-	var resp struct {
-		Data struct {
-			IssueCreate struct {
-				Success bool `json:"success"`
-				Issue   struct {
-					ID string `json:"id"`
-				} `json:"issue"`
-			} `json:"issueCreate"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
+	var data struct {
+		IssueCreate struct {
+			Success bool `json:"success"`
+			Issue   struct {
+				ID string `json:"id"`
+			} `json:"issue"`
+		} `json:"issueCreate"`
 	}
-	if err := json.Unmarshal(body, &resp); err != nil {
+	if err := q.query(ctx, mutation, variables, &data); err != nil {
 		return "", err
 	}
-	if len(resp.Errors) > 0 {
-		return "", fmt.Errorf("issueCreate failed: %s", resp.Errors[0].Message)
-	}
-	if !resp.Data.IssueCreate.Success {
+	if !data.IssueCreate.Success {
 		return "", fmt.Errorf("failed to create issue from template %s", templateID)
 	}
-	return resp.Data.IssueCreate.Issue.ID, nil
+	return data.IssueCreate.Issue.ID, nil
 }
 
 // subIssue represents a sub-issue fetched from the API.
 type subIssue struct {
-	id    string
-	title string
+	id        string
+	title     string
+	blocks    []string // IDs of the issues this one blocks
+	blockedBy []string // IDs of the issues that block this one
 }
 
-// getChildIssues fetches all sub-issues (children) of the given parent issue.
-func getChildIssues(q q, parentID string) ([]subIssue, error) {
+// relationPage is a page of an issue's relations or inverseRelations. The
+// other issue of a relation is relatedIssue for relations and issue for
+// inverseRelations.
+type relationPage struct {
+	Nodes []struct {
+		Type         string
+		Issue        struct{ ID string }
+		RelatedIssue struct{ ID string }
+	}
+	PageInfo struct {
+		HasNextPage bool
+		EndCursor   string
+	}
+}
+
+// getChildIssues fetches all sub-issues (children) of the given parent issue
+// with the blocks relations they are in.
+func getChildIssues(ctx context.Context, q q, parentID string) ([]subIssue, error) {
+	if children, ok := q.dryRun.plannedChildren(parentID); ok {
+		return children, nil
+	}
 	query := `query GetChildren($issueId: String!, $after: String) {
 		issue(id: $issueId) {
 			children(first: 50, after: $after) {
 				nodes {
 					id
 					title
+					relations(first: 50) {
+						nodes {
+							type
+							relatedIssue { id }
+						}
+						pageInfo { hasNextPage endCursor }
+					}
+					inverseRelations(first: 50) {
+						nodes {
+							type
+							issue { id }
+						}
+						pageInfo { hasNextPage endCursor }
+					}
 				}
 				pageInfo {
 					hasNextPage
@@ -389,44 +594,104 @@ func getChildIssues(q q, parentID string) ([]subIssue, error) {
 		if cursor != "" {
 			vars["after"] = cursor
 		}
-		body, err := q.do(query, vars)
-		if err != nil {
-			return nil, err
-		}
-
-		var resp struct {
-			Data struct {
-				Issue struct {
-					Children struct {
-						Nodes []struct {
-							ID    string
-							Title string
-						}
-						PageInfo struct {
-							HasNextPage bool
-							EndCursor   string
-						}
+		var data struct {
+			Issue struct {
+				Children struct {
+					Nodes []struct {
+						ID               string
+						Title            string
+						Relations        relationPage
+						InverseRelations relationPage
+					}
+					PageInfo struct {
+						HasNextPage bool
+						EndCursor   string
 					}
 				}
 			}
 		}
-		if err := json.Unmarshal(body, &resp); err != nil {
+		if err := q.query(ctx, query, vars, &data); err != nil {
 			return nil, err
 		}
 
-		for _, n := range resp.Data.Issue.Children.Nodes {
-			out = append(out, subIssue{id: n.ID, title: n.Title})
+		for _, n := range data.Issue.Children.Nodes {
+			child := subIssue{id: n.ID, title: n.Title}
+			var err error
+			child.blocks, err = blocksRelations(ctx, q, n.ID, "relations", n.Relations)
+			if err != nil {
+				return nil, err
+			}
+			child.blockedBy, err = blocksRelations(ctx, q, n.ID, "inverseRelations", n.InverseRelations)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, child)
 		}
 
-		if !resp.Data.Issue.Children.PageInfo.HasNextPage {
+		if !data.Issue.Children.PageInfo.HasNextPage {
 			return out, nil
 		}
-		cursor = resp.Data.Issue.Children.PageInfo.EndCursor
+		cursor = data.Issue.Children.PageInfo.EndCursor
+	}
+}
+
+// blocksRelations returns the IDs of the other issues in the blocks relations
+// of an issue, starting with first, the first page of its relations or
+// inverseRelations field, and fetching the rest.
+func blocksRelations(ctx context.Context, q q, issueID, field string, first relationPage) ([]string, error) {
+	query := fmt.Sprintf(`query GetRelations($issueId: String!, $relationsAfter: String) {
+		issue(id: $issueId) {
+			%s(first: 50, after: $relationsAfter) {
+				nodes {
+					type
+					issue { id }
+					relatedIssue { id }
+				}
+				pageInfo { hasNextPage endCursor }
+			}
+		}
+	}`, field)
+
+	var ids []string
+	page := first
+	for {
+		for _, rel := range page.Nodes {
+			if rel.Type != "blocks" {
+				continue
+			}
+			if field == "relations" {
+				ids = append(ids, rel.RelatedIssue.ID)
+			} else {
+				ids = append(ids, rel.Issue.ID)
+			}
+		}
+		if !page.PageInfo.HasNextPage {
+			return ids, nil
+		}
+
+		vars := map[string]any{"issueId": issueID, "relationsAfter": page.PageInfo.EndCursor}
+		var data struct {
+			Issue struct {
+				Relations        relationPage
+				InverseRelations relationPage
+			}
+		}
+		if err := q.query(ctx, query, vars, &data); err != nil {
+			return nil, err
+		}
+		page = data.Issue.Relations
+		if field == "inverseRelations" {
+			page = data.Issue.InverseRelations
+		}
 	}
 }
 
 // createBlocksRelation creates a "blocks" relation: blocker blocks blocked.
-func createBlocksRelation(q q, blockerID, blockedID string) error {
+func createBlocksRelation(ctx context.Context, q q, blockerID, blockedID string) error {
+	if q.dryRun != nil {
+		q.dryRun.createBlocksRelation(blockerID, blockedID)
+		return nil
+	}
 	mutation := `mutation CreateRelation($input: IssueRelationCreateInput!) {
 		issueRelationCreate(input: $input) {
 			success
@@ -439,29 +704,134 @@ func createBlocksRelation(q q, blockerID, blockedID string) error {
 		"type":           "blocks",
 	}
 
-	body, err := q.do(mutation, map[string]any{"input": input})
-	if err != nil {
+	var data struct {
+		IssueRelationCreate struct {
+			Success bool `json:"success"`
+		} `json:"issueRelationCreate"`
+	}
+	if err := q.query(ctx, mutation, map[string]any{"input": input}, &data); err != nil {
 		return err
 	}
+	if !data.IssueRelationCreate.Success {
+		return fmt.Errorf("issueRelationCreate returned success=false")
+	}
+	return nil
+}
 
-	var resp struct {
-		Data struct {
-			IssueRelationCreate struct {
-				Success bool `json:"success"`
-			} `json:"issueRelationCreate"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
+// deleteIssue moves an issue to the trash, from which it can be restored for a
+// while.
+func deleteIssue(ctx context.Context, q q, issueID string) error {
+	if q.dryRun != nil {
+		q.dryRun.deleteIssue(issueID)
+		return nil
 	}
-	if err := json.Unmarshal(body, &resp); err != nil {
+	mutation := `mutation IssueDelete($id: String!) {
+		issueDelete(id: $id) {
+			success
+		}
+	}`
+	var data struct {
+		IssueDelete struct {
+			Success bool `json:"success"`
+		} `json:"issueDelete"`
+	}
+	if err := q.query(ctx, mutation, map[string]any{"id": issueID}, &data); err != nil {
 		return err
 	}
-	if len(resp.Errors) > 0 {
-		return fmt.Errorf("issueRelationCreate failed: %s", resp.Errors[0].Message)
+	if !data.IssueDelete.Success {
+		return fmt.Errorf("failed to delete issue %s", issueID)
 	}
-	if !resp.Data.IssueRelationCreate.Success {
-		return fmt.Errorf("issueRelationCreate returned success=false")
+	return nil
+}
+
+// getIssueID resolves an issue ID or identifier such as "ENG-123" to an ID.
+func getIssueID(ctx context.Context, q q, ref string) (string, error) {
+	query := `query GetIssue($id: String!) {
+		issue(id: $id) {
+			id
+		}
+	}`
+	var data struct {
+		Issue struct {
+			ID string
+		}
+	}
+	if err := q.query(ctx, query, map[string]any{"id": ref}, &data); err != nil {
+		return "", err
+	}
+	return data.Issue.ID, nil
+}
+
+// getIssueComments returns the bodies of an issue's comments, oldest first.
+func getIssueComments(ctx context.Context, q q, issueID string) ([]string, error) {
+	if q.dryRun.planned(issueID) {
+		return nil, nil
+	}
+	query := `query GetComments($issueId: String!, $after: String) {
+		issue(id: $issueId) {
+			comments(first: 50, after: $after, orderBy: createdAt) {
+				nodes { body }
+				pageInfo { hasNextPage endCursor }
+			}
+		}
+	}`
+
+	var out []string
+	cursor := ""
+	for {
+		vars := map[string]any{"issueId": issueID}
+		if cursor != "" {
+			vars["after"] = cursor
+		}
+		var data struct {
+			Issue struct {
+				Comments struct {
+					Nodes []struct {
+						Body string
+					}
+					PageInfo struct {
+						HasNextPage bool
+						EndCursor   string
+					}
+				}
+			}
+		}
+		if err := q.query(ctx, query, vars, &data); err != nil {
+			return nil, err
+		}
+
+		for _, n := range data.Issue.Comments.Nodes {
+			out = append(out, n.Body)
+		}
+
+		if !data.Issue.Comments.PageInfo.HasNextPage {
+			return out, nil
+		}
+		cursor = data.Issue.Comments.PageInfo.EndCursor
+	}
+}
+
+func createComment(ctx context.Context, q q, issueID, body string) error {
+	if q.dryRun != nil {
+		q.dryRun.createComment(issueID, body)
+		return nil
+	}
+	mutation := `mutation CommentCreate($input: CommentCreateInput!) {
+		commentCreate(input: $input) {
+			success
+		}
+	}`
+	var data struct {
+		CommentCreate struct {
+			Success bool `json:"success"`
+		} `json:"commentCreate"`
+	}
+	input := map[string]any{"issueId": issueID, "body": body}
+	if err := q.query(ctx, mutation, map[string]any{"input": input}, &data); err != nil {
+		return err
+	}
+	if !data.CommentCreate.Success {
+		return fmt.Errorf("failed to comment on issue %s", issueID)
 	}
 	return nil
 }
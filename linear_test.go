@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestQ_CustomEndpointAndClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "lin_api_test", r.Header.Get("Authorization"))
+		var req struct {
+			Variables map[string]any `json:"variables"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "Platform", req.Variables["teamName"])
+		w.Write([]byte(`{"data":{"teams":{"nodes":[{"id":"team-1"}]}}}`))
+	}))
+	defer srv.Close()
+
+	q := q{token: "lin_api_test", endpoint: srv.URL, client: srv.Client()}
+	teamID, err := getTeamID(context.Background(), q, "Platform")
+	assert.NoError(t, err)
+	assert.Equal(t, "team-1", teamID)
+}
+
+func TestQ_PartialDataWithErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"data": {"teams": {"nodes": [{"id": "team-1"}]}},
+			"errors": [
+				{"message": "Field is deprecated", "path": ["teams", "nodes", 0, "key"], "extensions": {"code": "GRAPHQL_VALIDATION_FAILED"}},
+				{"message": "Not allowed", "extensions": {"code": "FORBIDDEN"}}
+			]
+		}`))
+	}))
+	defer srv.Close()
+
+	q := q{endpoint: srv.URL, client: srv.Client()}
+	_, err := getTeamID(context.Background(), q, "Platform")
+	var apiErr *apiError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "GetTeam", apiErr.operation)
+	assert.Equal(t, "GRAPHQL_VALIDATION_FAILED", apiErr.code())
+	assert.Equal(t, "FORBIDDEN", apiErr.errors[1].Extensions.Code)
+	assert.Equal(t, []any{"teams", "nodes", 0.0, "key"}, apiErr.errors[0].Path)
+	assert.Equal(t, "GetTeam failed: Field is deprecated at teams.nodes.0.key (GRAPHQL_VALIDATION_FAILED) (and 1 more)", err.Error())
+	assert.Equal(t, `{"teams": {"nodes": [{"id": "team-1"}]}}`, string(apiErr.data))
+}
+
+func TestQ_ErrorsFromEveryCall(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	ctx := context.Background()
+	for _, call := range []struct {
+		operation string
+		run       func() error
+	}{
+		{"GetTeam", func() error { _, err := getTeamID(ctx, q, "Test Team"); return err }},
+		{"SearchIssues", func() error { _, err := searchTeamIssues(ctx, q, "team-1", "x"); return err }},
+		{"IssueUpdate", func() error { return updateTitle(ctx, q, "issue-1", "x") }},
+		{"Templates", func() error { _, err := getTemplates(ctx, q); return err }},
+		{"IssuesCreatedBetween", func() error {
+			_, err := getTemplateCreatedIssueCounts(ctx, q, "team-1", time.Now(), time.Now())
+			return err
+		}},
+		{"IssueCreateFromTemplate", func() error {
+			_, err := createIssueFromTemplate(ctx, q, "template-1", "team-1", time.Time{})
+			return err
+		}},
+		{"GetChildren", func() error { _, err := getChildIssues(ctx, q, "issue-1"); return err }},
+		{"CreateRelation", func() error { return createBlocksRelation(ctx, q, "issue-1", "issue-2") }},
+	} {
+		fake.failures = []fakeFailure{{status: http.StatusOK, code: "FORBIDDEN"}}
+		var apiErr *apiError
+		assert.True(t, errors.As(call.run(), &apiErr), call.operation)
+		assert.Equal(t, call.operation, apiErr.operation)
+		assert.Equal(t, "FORBIDDEN", apiErr.code())
+	}
+}
+
+func TestQ_ErrorFromFake(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	fake.addTemplate("team-1", "T", "", "Child")
+	issueID, err := createIssueFromTemplate(context.Background(), q, "template-1", "team-1", time.Time{})
+	assert.NoError(t, err)
+	children, err := getChildIssues(context.Background(), q, issueID)
+	assert.NoError(t, err)
+	assert.NoError(t, createBlocksRelation(context.Background(), q, children[0].id, issueID))
+
+	err = createBlocksRelation(context.Background(), q, children[0].id, issueID)
+	var apiErr *apiError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "INVALID_INPUT", apiErr.code())
+	assert.Equal(t, []any{"issueRelationCreate"}, apiErr.errors[0].Path)
+}
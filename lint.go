@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// lintFinding is a problem lint found with a template.
+type lintFinding struct {
+	TemplateID string `json:"templateId"`
+	Template   string `json:"template"`
+	Check      string `json:"check"`
+	Severity   string `json:"severity"` // error or warning
+	Message    string `json:"message"`
+}
+
+// runLint checks every template and reports what is wrong with them. It exits
+// with status 2 if there are errors and 1 if there are only warnings, counting
+// only findings of cfg.failOn severity or worse, and with status 3 if it could
+// not check the templates. The lint command also exits with status 3 if it
+// cannot start.
+func runLint(ctx context.Context, token string, cfg config) int {
+	q := cfg.newQ(token)
+	templates, err := getTemplates(ctx, q)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list templates: %v\n", err)
+		return 3
+	}
+	teams, err := getTeams(ctx, q)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list teams: %v\n", err)
+		return 3
+	}
+	teamIDs := map[string]bool{}
+	for _, t := range teams {
+		teamIDs[t.id] = true
+	}
+
+	findings := lintTemplates(templates, teamIDs, cfg)
+	switch cfg.format {
+	case "json", "yaml":
+		err = writeFormatted(os.Stdout, cfg.format, findings)
+	case "sarif":
+		err = writeFormatted(os.Stdout, "json", sarifLog(findings))
+	default:
+		writeLintText(os.Stdout, findings)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write findings: %v\n", err)
+		return 3
+	}
+
+	status := 0
+	for _, f := range findings {
+		switch {
+		case f.Severity == "error" && cfg.failOn != "none":
+			return 2
+		case f.Severity == "warning" && cfg.failOn == "warning":
+			status = 1
+		}
+	}
+	return status
+}
+
+// lintTemplates checks templates for malformed schedule lines, a missing
+// schedule, Once dates in the past, sub-issue prefix problems and teams that
+// are not among teamIDs.
+func lintTemplates(templates []issueTemplate, teamIDs map[string]bool, cfg config) []lintFinding {
+	findings := []lintFinding{}
+	for _, t := range templates {
+		add := func(check string, severity problemSeverity, format string, args ...any) {
+			findings = append(findings, lintFinding{
+				TemplateID: t.id,
+				Template:   t.name,
+				Check:      check,
+				Severity:   severity.String(),
+				Message:    fmt.Sprintf(format, args...),
+			})
+		}
+
+		if t.teamID != "" && !teamIDs[t.teamID] {
+			add("missing-team", severityError, "team %s no longer exists", t.teamID)
+		}
+
+		ts := parseTemplateSchedule(t.description, cfg.calendars)
+		today := civilDate(cfg.now(ts.locationOr(cfg.location)))
+		if len(ts.schedules) == 0 {
+			add("no-schedule", severityWarning, "no Recurrence: or At: line, so no issues are ever created from it")
+		}
+		for _, s := range ts.schedules {
+			switch {
+			case s.kind == scheduleMalformed && s.problem != "":
+				add("malformed-schedule", severityError, "malformed line %q: %s", s.raw, s.problem)
+			case s.kind == scheduleMalformed:
+				add("malformed-schedule", severityError, "malformed line %q", s.raw)
+			case s.kind == scheduleAt && s.date.Before(today):
+				add("past-date", severityWarning, "At: %s is in the past", s.date.Format("2006-01-02"))
+			}
+		}
+
+		for _, p := range validateSubIssuePrefixes(t.subIssueTitles) {
+			add(p.check, p.severity, "sub-issue %q: %s", p.title, p.problem)
+		}
+	}
+	return findings
+}
+
+func writeLintText(w io.Writer, findings []lintFinding) {
+	errors, warnings := 0, 0
+	for _, f := range findings {
+		fmt.Fprintf(w, "%s: %s: %s [%s]\n", f.Template, f.Severity, f.Message, f.Check)
+		if f.Severity == "error" {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	fmt.Fprintf(w, "%d errors, %d warnings\n", errors, warnings)
+}
+
+// sarifLog returns findings as a SARIF 2.1.0 log, for code scanning tools.
+// Templates are not files, so results carry logical locations only.
+func sarifLog(findings []lintFinding) any {
+	type sarifLocation struct {
+		LogicalLocations []map[string]string `json:"logicalLocations"`
+	}
+	type sarifResult struct {
+		RuleID    string            `json:"ruleId"`
+		Level     string            `json:"level"`
+		Message   map[string]string `json:"message"`
+		Locations []sarifLocation   `json:"locations"`
+	}
+	type sarifRule struct {
+		ID string `json:"id"`
+	}
+
+	results := []sarifResult{}
+	rules := []sarifRule{}
+	seen := map[string]bool{}
+	for _, f := range findings {
+		results = append(results, sarifResult{
+			RuleID:  f.Check,
+			Level:   f.Severity,
+			Message: map[string]string{"text": f.Message},
+			Locations: []sarifLocation{{LogicalLocations: []map[string]string{
+				{"name": f.Template, "fullyQualifiedName": f.TemplateID, "kind": "template"},
+			}}},
+		})
+		if !seen[f.Check] {
+			seen[f.Check] = true
+			rules = append(rules, sarifRule{ID: f.Check})
+		}
+	}
+
+	return map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []any{map[string]any{
+			"tool":    map[string]any{"driver": map[string]any{"name": "linear-future", "rules": rules}},
+			"results": results,
+		}},
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestLintTemplates(t *testing.T) {
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	cfg := config{location: time.UTC, clock: fixedClock(now)}
+	templates := []issueTemplate{
+		{id: "t1", name: "Fine", teamID: "team-1", description: "Recurrence: Mon", subIssueTitles: []string{"1|REQ A"}},
+		{id: "t2", name: "Broken", teamID: "team-1", description: "Recurrence: someday\nAt: 2026-01-05\nAt: 2026-06-01"},
+		{id: "t3", name: "Unscheduled", teamID: "team-gone", description: "Notes"},
+		{id: "t4", name: "Cycle", description: "Recurrence: daily", subIssueTitles: []string{"1|DEPS2 A", "2|DEPS1 B"}},
+	}
+
+	findings := lintTemplates(templates, map[string]bool{"team-1": true}, cfg)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Template+" "+f.Severity+" "+f.Check)
+	}
+	assert.Equal(t, []string{
+		"Broken error malformed-schedule",
+		"Broken warning past-date",
+		"Unscheduled error missing-team",
+		"Unscheduled warning no-schedule",
+		"Cycle error dependency-cycle",
+	}, got)
+	assert.Equal(t, `At: 2026-01-05 is in the past`, findings[1].Message)
+}
+
+func TestLintOutput(t *testing.T) {
+	findings := []lintFinding{
+		{TemplateID: "t1", Template: "Weekly", Check: "past-date", Severity: "warning", Message: "At: 2026-01-05 is in the past"},
+	}
+
+	var b strings.Builder
+	writeLintText(&b, findings)
+	assert.Equal(t, "Weekly: warning: At: 2026-01-05 is in the past [past-date]\n0 errors, 1 warnings\n", b.String())
+
+	b.Reset()
+	assert.NoError(t, writeFormatted(&b, "json", sarifLog(findings)))
+	var log struct {
+		Version string
+		Runs    []struct {
+			Results []struct {
+				RuleID    string
+				Level     string
+				Locations []struct {
+					LogicalLocations []struct{ FullyQualifiedName string }
+				}
+			}
+		}
+	}
+	assert.NoError(t, json.Unmarshal([]byte(b.String()), &log))
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Equal(t, "past-date", log.Runs[0].Results[0].RuleID)
+	assert.Equal(t, "warning", log.Runs[0].Results[0].Level)
+	assert.Equal(t, "t1", log.Runs[0].Results[0].Locations[0].LogicalLocations[0].FullyQualifiedName)
+}
+
+func TestRunLint_ExitStatus(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	cfg := config{location: time.UTC, clock: fixedClock(now), endpoint: q.endpoint, client: q.client, format: "json"}
+	exitStatus := func(failOn string) int {
+		cfg := cfg
+		cfg.failOn = failOn
+		return runLint(context.Background(), q.token, cfg)
+	}
+	fake.addTemplate("team-1", "Fine", "Recurrence: Mon")
+	fake.addTemplate("team-1", "Unscheduled", "")
+
+	assert.Equal(t, 0, exitStatus("error"))
+	assert.Equal(t, 1, exitStatus("warning"))
+
+	fake.addTemplate("team-2", "Other team", "Recurrence: daily")
+	assert.Equal(t, 2, exitStatus("error"))
+	assert.Equal(t, 2, exitStatus("warning"))
+	assert.Equal(t, 0, exitStatus("none"))
+
+	cfg.endpoint = "http://127.0.0.1:1"
+	assert.Equal(t, 3, exitStatus("none"))
+}
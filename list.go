@@ -1,20 +1,27 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 )
 
-func runList(token string) int {
-	q := q{token}
-	templates, err := getTemplates(q)
+func runList(ctx context.Context, token string, cfg config) int {
+	q := cfg.newQ(token)
+	templates, err := getTemplates(ctx, q)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to list templates: %v\n", err)
 		return 1
 	}
-
-	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if cfg.format == "json" || cfg.format == "yaml" {
+		if err := writeFormatted(os.Stdout, cfg.format, templateInfos(templates, cfg)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list templates: %v\n", err)
+			return 1
+		}
+		return 0
+	}
 
 	for _, t := range templates {
 		fmt.Printf("%s\n", t.name)
@@ -22,18 +29,35 @@ func runList(token string) int {
 			fmt.Printf("  Issue: %s\n", t.issueTitle)
 		}
 
-		schedules := parseSchedules(t.description)
-		if len(schedules) == 0 {
+		ts := parseTemplateSchedule(t.description, cfg.calendars)
+		today := civilDate(cfg.now(ts.locationOr(cfg.location)))
+		if len(ts.schedules) == 0 {
 			fmt.Println("  **NO SCHEDULE**")
 		} else {
-			for _, s := range schedules {
+			for _, s := range ts.schedules {
 				fmt.Printf("  %s\n", formatSchedule(s))
 			}
-			dates := nextTriggerDates(schedules, today, 365, 5)
+			if ts.adjust != adjustNone {
+				fmt.Printf("  %s\n", formatAdjustment(ts))
+			}
+			if ts.location != nil || ts.timeOfDay != 0 {
+				fmt.Printf("  At %s %s\n", formatTimeOfDay(ts.timeOfDay), ts.locationOr(cfg.location))
+			}
+			if bounds := formatBounds(ts); bounds != "" {
+				fmt.Printf("  %s\n", bounds)
+			}
+			if ts.expired(today) {
+				fmt.Printf("  **EXPIRED** since %s\n", ts.endBy(today).Format("2006-01-02"))
+			}
+			dates := nextTriggerDates(ts, today, 365, 5)
 			if len(dates) > 0 {
 				fmt.Print("  Upcoming:")
 				for _, d := range dates {
-					fmt.Printf(" %s", d.Format("2006-01-02"))
+					if ts.excepted(d) {
+						fmt.Printf(" %s(excepted)", d.Format("2006-01-02"))
+					} else {
+						fmt.Printf(" %s", d.Format("2006-01-02"))
+					}
 				}
 				fmt.Println()
 			}
@@ -46,13 +70,182 @@ func runList(token string) int {
 			}
 			problems := validateSubIssuePrefixes(t.subIssueTitles)
 			for _, p := range problems {
-				fmt.Printf("  **INVALID**: %s: %s\n", p.title, p.problem)
+				if p.severity == severityWarning {
+					fmt.Printf("  **WARNING**: %s: %s\n", p.title, p.problem)
+				} else {
+					fmt.Printf("  **INVALID**: %s: %s\n", p.title, p.problem)
+				}
 			}
 		}
 	}
 	return 0
 }
 
+// templateInfo is what -list -format json|yaml shows for a template.
+type templateInfo struct {
+	ID         string          `json:"id"`
+	TeamID     string          `json:"teamId"`
+	Name       string          `json:"name"`
+	IssueTitle string          `json:"issueTitle"`
+	Schedules  []scheduleInfo  `json:"schedules"`
+	Malformed  []malformedInfo `json:"malformed"`
+	Adjust     string          `json:"adjust,omitempty"` // nextBusinessDay or previousBusinessDay
+	Timezone   string          `json:"timezone"`
+	Time       string          `json:"time"` // HH:MM
+	From       string          `json:"from,omitempty"`
+	Until      string          `json:"until,omitempty"`
+	Count      int             `json:"count,omitempty"`
+	Expired    bool            `json:"expired"`
+	Upcoming   []upcomingInfo  `json:"upcoming"`
+	SubIssues  []subIssueInfo  `json:"subIssues"`
+	Problems   []problemInfo   `json:"problems"`
+}
+
+// scheduleInfo is a schedule line. Only the parameters of its kind are set.
+type scheduleInfo struct {
+	Kind     string `json:"kind"`
+	Text     string `json:"text"`
+	Weekday  string `json:"weekday,omitempty"`
+	Nth      int    `json:"nth,omitempty"` // -1 for last
+	Day      int    `json:"day,omitempty"`
+	Month    string `json:"month,omitempty"`
+	Date     string `json:"date,omitempty"`
+	Interval int    `json:"interval,omitempty"`
+	Unit     string `json:"unit,omitempty"`
+	Cron     string `json:"cron,omitempty"`
+	RRule    string `json:"rrule,omitempty"`
+}
+
+type malformedInfo struct {
+	Line    string `json:"line"`
+	Problem string `json:"problem,omitempty"`
+}
+
+type upcomingInfo struct {
+	Date     string `json:"date"`
+	Excepted bool   `json:"excepted"`
+}
+
+type subIssueInfo struct {
+	Title         string `json:"title"`
+	ID            int    `json:"id,omitempty"`
+	Req           bool   `json:"req"`
+	Needs         []int  `json:"needs"`
+	StrippedTitle string `json:"strippedTitle"`
+}
+
+type problemInfo struct {
+	Title    string `json:"title"`
+	Problem  string `json:"problem"`
+	Severity string `json:"severity"`
+}
+
+var scheduleKindNames = map[scheduleKind]string{
+	scheduleDaily:           "daily",
+	scheduleWeekday:         "weekday",
+	scheduleDayOfMonth:      "dayOfMonth",
+	scheduleLastDayOfMonth:  "lastDayOfMonth",
+	scheduleMonthDay:        "monthDay",
+	scheduleMonthLast:       "monthLast",
+	scheduleAt:              "at",
+	scheduleEvery:           "every",
+	scheduleNthWeekday:      "nthWeekday",
+	scheduleMonthNthWeekday: "monthNthWeekday",
+	scheduleCron:            "cron",
+	scheduleRRule:           "rrule",
+}
+
+func templateInfos(templates []issueTemplate, cfg config) []templateInfo {
+	infos := make([]templateInfo, 0, len(templates))
+	for _, t := range templates {
+		ts := parseTemplateSchedule(t.description, cfg.calendars)
+		today := civilDate(cfg.now(ts.locationOr(cfg.location)))
+		info := templateInfo{
+			ID:         t.id,
+			TeamID:     t.teamID,
+			Name:       t.name,
+			IssueTitle: t.issueTitle,
+			Schedules:  []scheduleInfo{},
+			Malformed:  []malformedInfo{},
+			Timezone:   ts.locationOr(cfg.location).String(),
+			Time:       formatTimeOfDay(ts.timeOfDay),
+			Count:      ts.count,
+			Expired:    ts.expired(today),
+			Upcoming:   []upcomingInfo{},
+			SubIssues:  []subIssueInfo{},
+			Problems:   []problemInfo{},
+		}
+		for _, s := range ts.schedules {
+			if s.kind == scheduleMalformed {
+				info.Malformed = append(info.Malformed, malformedInfo{Line: s.raw, Problem: s.problem})
+				continue
+			}
+			info.Schedules = append(info.Schedules, scheduleInfoOf(s))
+		}
+		switch ts.adjust {
+		case adjustNextBusinessDay:
+			info.Adjust = "nextBusinessDay"
+		case adjustPreviousBusinessDay:
+			info.Adjust = "previousBusinessDay"
+		}
+		if !ts.from.IsZero() {
+			info.From = ts.from.Format("2006-01-02")
+		}
+		if !ts.until.IsZero() {
+			info.Until = ts.until.Format("2006-01-02")
+		}
+		if len(ts.schedules) > 0 {
+			for _, d := range nextTriggerDates(ts, today, 365, 5) {
+				info.Upcoming = append(info.Upcoming, upcomingInfo{Date: d.Format("2006-01-02"), Excepted: ts.excepted(d)})
+			}
+		}
+		for _, title := range t.subIssueTitles {
+			sub := subIssueInfo{Title: title, Needs: []int{}, StrippedTitle: title}
+			if p, err := parseSubIssuePrefix(title); err == nil {
+				sub.ID, sub.Req, sub.StrippedTitle = p.id, p.req, p.title
+				if p.needs != nil {
+					sub.Needs = p.needs
+				}
+			}
+			info.SubIssues = append(info.SubIssues, sub)
+		}
+		for _, p := range validateSubIssuePrefixes(t.subIssueTitles) {
+			info.Problems = append(info.Problems, problemInfo{Title: p.title, Problem: p.problem, Severity: p.severity.String()})
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func scheduleInfoOf(s schedule) scheduleInfo {
+	info := scheduleInfo{Kind: scheduleKindNames[s.kind], Text: formatSchedule(s)}
+	switch s.kind {
+	case scheduleWeekday:
+		info.Weekday = s.weekday.String()
+	case scheduleDayOfMonth:
+		info.Day = s.day
+	case scheduleMonthDay:
+		info.Month, info.Day = s.month.String(), s.day
+	case scheduleMonthLast:
+		info.Month = s.month.String()
+	case scheduleAt:
+		info.Date = s.date.Format("2006-01-02")
+	case scheduleEvery:
+		info.Interval = s.interval
+		info.Unit = map[intervalUnit]string{unitDay: "day", unitWeek: "week", unitMonth: "month"}[s.unit]
+		info.Date = s.date.Format("2006-01-02")
+	case scheduleNthWeekday:
+		info.Nth, info.Weekday = s.nth, s.weekday.String()
+	case scheduleMonthNthWeekday:
+		info.Nth, info.Weekday, info.Month = s.nth, s.weekday.String(), s.month.String()
+	case scheduleCron:
+		info.Cron = s.cron.expr
+	case scheduleRRule:
+		info.RRule = s.rrule.text
+	}
+	return info
+}
+
 func formatSchedule(s schedule) string {
 	switch s.kind {
 	case scheduleDaily:
@@ -69,26 +262,87 @@ func formatSchedule(s schedule) string {
 		return fmt.Sprintf("Last day of %s", s.month)
 	case scheduleAt:
 		return fmt.Sprintf("Once on %s", s.date.Format("2006-01-02"))
+	case scheduleEvery:
+		return fmt.Sprintf("Every %s from %s", formatInterval(s.interval, s.unit), s.date.Format("2006-01-02"))
+	case scheduleNthWeekday:
+		return fmt.Sprintf("%s %s of every month", formatOrdinal(s.nth), s.weekday)
+	case scheduleMonthNthWeekday:
+		return fmt.Sprintf("%s %s of %s", formatOrdinal(s.nth), s.weekday, s.month)
+	case scheduleCron:
+		return fmt.Sprintf("Cron %s", s.cron.expr)
+	case scheduleRRule:
+		return s.rrule.text
 	case scheduleMalformed:
+		if s.problem != "" {
+			return fmt.Sprintf("**MALFORMED**: %s (%s)", s.raw, s.problem)
+		}
 		return fmt.Sprintf("**MALFORMED**: %s", s.raw)
 	default:
 		return "Unknown"
 	}
 }
 
-// nextTriggerDates returns up to maxDates matching dates within the next days from from.
-func nextTriggerDates(schedules []schedule, from time.Time, days int, maxDates int) []time.Time {
+func formatAdjustment(ts templateSchedule) string {
+	direction := "next"
+	if ts.adjust == adjustPreviousBusinessDay {
+		direction = "previous"
+	}
+	if len(ts.holidays) == 0 {
+		return fmt.Sprintf("Weekends move to the %s business day", direction)
+	}
+	return fmt.Sprintf("Weekends and %d holidays move to the %s business day", len(ts.holidays), direction)
+}
+
+// formatBounds describes the From:, Until: and Count: limits, or returns "" if there are none.
+func formatBounds(ts templateSchedule) string {
+	var parts []string
+	if !ts.from.IsZero() {
+		parts = append(parts, "from "+ts.from.Format("2006-01-02"))
+	}
+	if !ts.until.IsZero() {
+		parts = append(parts, "until "+ts.until.Format("2006-01-02"))
+	}
+	if ts.count > 0 {
+		parts = append(parts, fmt.Sprintf("at most %d times", ts.count))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	s := "Only " + strings.Join(parts, ", ")
+	if countEnd := ts.countEnd(); !countEnd.IsZero() {
+		s += fmt.Sprintf(" (last on %s)", countEnd.Format("2006-01-02"))
+	}
+	return s
+}
+
+func formatTimeOfDay(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
+
+func formatInterval(n int, unit intervalUnit) string {
+	name := map[intervalUnit]string{unitDay: "day", unitWeek: "week", unitMonth: "month"}[unit]
+	if n == 1 {
+		return name
+	}
+	return fmt.Sprintf("%d %ss", n, name)
+}
+
+func formatOrdinal(nth int) string {
+	return map[int]string{1: "First", 2: "Second", 3: "Third", 4: "Fourth", 5: "Fifth", -1: "Last"}[nth]
+}
+
+// nextTriggerDates returns up to maxDates dates within the next days from from
+// on which the template is due, after business-day adjustment. Dates suppressed
+// by Except: lines are included; check them with templateSchedule.excepted.
+func nextTriggerDates(ts templateSchedule, from time.Time, days int, maxDates int) []time.Time {
 	var dates []time.Time
 	for i := range days {
 		if len(dates) >= maxDates {
 			break
 		}
 		d := from.AddDate(0, 0, i)
-		for _, s := range schedules {
-			if s.matches(d) {
-				dates = append(dates, d)
-				break
-			}
+		if ts.due(d) {
+			dates = append(dates, d)
 		}
 	}
 	return dates
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestTemplateInfos(t *testing.T) {
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC) // Monday
+	cfg := config{location: time.UTC, clock: fixedClock(now)}
+	templates := []issueTemplate{{
+		id:             "template-1",
+		name:           "Weekly",
+		description:    "Recurrence: Mon\nRecurrence: 2nd Tue\nRecurrence: someday\nTime: 09:30",
+		teamID:         "team-1",
+		issueTitle:     "Weekly sync",
+		subIssueTitles: []string{"1|REQ Prepare", "2|DEPS1 Run", "Notes"},
+	}}
+
+	infos := templateInfos(templates, cfg)
+	assert.Equal(t, 1, len(infos))
+	info := infos[0]
+	assert.Equal(t, []scheduleInfo{
+		{Kind: "weekday", Text: "Every Monday", Weekday: "Monday"},
+		{Kind: "nthWeekday", Text: "Second Tuesday of every month", Weekday: "Tuesday", Nth: 2},
+	}, info.Schedules)
+	assert.Equal(t, 1, len(info.Malformed))
+	assert.Equal(t, "09:30", info.Time)
+	assert.Equal(t, "UTC", info.Timezone)
+	assert.Equal(t, []upcomingInfo{{Date: "2026-03-02"}, {Date: "2026-03-09"}, {Date: "2026-03-10"}, {Date: "2026-03-16"}, {Date: "2026-03-23"}}, info.Upcoming)
+	assert.Equal(t, []subIssueInfo{
+		{Title: "1|REQ Prepare", ID: 1, Req: true, Needs: []int{}, StrippedTitle: "Prepare"},
+		{Title: "2|DEPS1 Run", ID: 2, Needs: []int{1}, StrippedTitle: "Run"},
+		{Title: "Notes", Needs: []int{}, StrippedTitle: "Notes"},
+	}, info.SubIssues)
+	assert.Equal(t, []problemInfo{{
+		Title:    "2|DEPS1 Run",
+		Problem:  "sub-issue 2 is not REQ and no REQ sub-issue depends on it, so the parent does not wait for it",
+		Severity: "warning",
+	}}, info.Problems)
+
+	var b strings.Builder
+	assert.NoError(t, writeFormatted(&b, "yaml", infos))
+	assert.Contains(t, b.String(), "- id: template-1\n  teamId: team-1\n  name: Weekly\n")
+}
@@ -3,36 +3,207 @@ package main
 import (
 	"flag"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
+	"time"
+	_ "time/tzdata" // Timezone: lines must work on hosts without a zone database
 )
 
-func realMain() int {
-	listTemplates := flag.Bool("list-templates", false, "List all templates")
-	list := flag.Bool("list", false, "Show template schedules, trigger dates, and sub-issue validation")
-	flag.Parse()
+// config holds command-line settings that shape how templates are evaluated.
+type config struct {
+	calendars map[string]calendar
+	location  *time.Location // for templates without a Timezone: line
+
+	statePath      string // catch-up state file; catch-up is off if empty
+	maxCatchUpDays int    // how many days before today catch-up looks at
+
+	dryRun   bool // print what would be changed in Linear instead of changing it
+	rollback bool // delete an issue again if its sub-issue setup fails
+
+	clock func(loc *time.Location) time.Time // nil for the system clock
 
-	token := os.Getenv("LINEAR_API_KEY")
+	format string // output format of list, templates and lint: text, json, yaml or sarif
+	failOn string // lowest lint finding severity that fails: error, warning or none
 
-	if *listTemplates {
-		return runListTemplates(token)
+	forecastDays int // how many days from today forecast covers
+
+	endpoint string       // Linear GraphQL endpoint; the public API if empty
+	client   *http.Client // nil for http.DefaultClient
+	retry    retryPolicy
+	timeout  time.Duration // per API request attempt; no limit if zero
+
+	runTimeout time.Duration // for the whole command; no limit if zero
+}
+
+// newQ returns an API client for token that talks to the configured endpoint.
+func (c config) newQ(token string) q {
+	q := q{token: token, endpoint: c.endpoint, client: c.client, retry: c.retry, timeout: c.timeout}
+	if c.dryRun {
+		q.dryRun = newDryRun()
+	}
+	return q
+}
+
+// now returns the current time in loc according to the configured clock.
+func (c config) now(loc *time.Location) time.Time {
+	if c.clock == nil {
+		return time.Now().In(loc)
 	}
-	if *list {
-		return runList(token)
+	return c.clock(loc)
+}
+
+// dateClock returns a clock pinned to the last second of date, in whatever
+// time zone it is asked about, so everything due that day counts as due.
+func dateClock(date time.Time) func(loc *time.Location) time.Time {
+	return func(loc *time.Location) time.Time {
+		return time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, loc)
 	}
+}
+
+// options holds the values of the command-line flags. Each command registers
+// the flags it takes with the add*Flags methods.
+type options struct {
+	calendars      calendarFlag
+	tz             string
+	asOf           string
+	statePath      string
+	maxCatchUpDays int
+	dryRun         bool
+	rollback       bool
+	format         choiceFlag
+	failOn         choiceFlag
+	forecastDays   int
+	endpoint       string
+	proxy          string
+	retries        int
+	maxRetryDelay  time.Duration
+	timeout        time.Duration
+	requestTimeout time.Duration
+}
 
-	if token == "" || flag.NArg() < 1 {
-		fmt.Fprintf(os.Stderr, "Usage: LINEAR_API_KEY=lin_api_... linear-future [flags] <team name>...\n")
-		flag.PrintDefaults()
-		return 2
+func newOptions() *options {
+	return &options{
+		calendars: calendarFlag{},
+		format:    choiceFlag{value: "text"},
+		failOn:    choiceFlag{value: "error", choices: []string{"error", "warning", "none"}},
 	}
-	retCode := 0
-	for _, teamName := range flag.Args() {
-		if err := createScheduledTeamIssues(token, teamName); err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			retCode = 1
+}
+
+func (o *options) addAPIFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.endpoint, "endpoint", os.Getenv("LINEAR_API_URL"), "Linear GraphQL endpoint `URL` (default $LINEAR_API_URL or "+defaultEndpoint+")")
+	fs.StringVar(&o.proxy, "proxy", "", "Send API requests through this HTTP proxy `URL` instead of the one from $HTTPS_PROXY")
+	fs.IntVar(&o.retries, "retries", defaultRetryPolicy.maxAttempts-1, "How many times to retry a failed API request")
+	fs.DurationVar(&o.maxRetryDelay, "max-retry-delay", defaultRetryPolicy.maxDelay, "Longest to wait before a retry, including waits for rate limits to reset")
+	fs.DurationVar(&o.timeout, "timeout", 0, "Give up after this long (default no limit)")
+	fs.DurationVar(&o.requestTimeout, "request-timeout", 30*time.Second, "Give up on a single API request after this long, and retry it if it is safe to")
+}
+
+func (o *options) addScheduleFlags(fs *flag.FlagSet) {
+	o.addCalendarFlags(fs)
+	fs.StringVar(&o.asOf, "date", "", "Act as if today were this `YYYY-MM-DD` date")
+}
+
+func (o *options) addCalendarFlags(fs *flag.FlagSet) {
+	fs.Var(o.calendars, "calendar", "Load a holiday calendar for Except: and Holidays: lines from a date list or .ics `[name=]file`; repeatable")
+	fs.StringVar(&o.tz, "tz", "UTC", "Default time zone for templates without a Timezone: line")
+}
+
+func (o *options) addRunFlags(fs *flag.FlagSet) {
+	o.addCatchUpFlags(fs)
+	fs.BoolVar(&o.rollback, "rollback", false, "Delete a new issue and its sub-issues again if setting up their dependencies fails, so the next run starts afresh")
+	o.addDryRunFlag(fs)
+}
+
+func (o *options) addCatchUpFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.statePath, "state", "", "Catch up on occurrences missed since the last successful run recorded in this `file`")
+	fs.IntVar(&o.maxCatchUpDays, "max-catch-up", 7, "Maximum number of `days` before today to catch up on")
+}
+
+func (o *options) addDryRunFlag(fs *flag.FlagSet) {
+	fs.BoolVar(&o.dryRun, "dry-run", false, "Read from Linear, but only print the issues, relations and renames that would be made")
+}
+
+func (o *options) addFormatFlag(fs *flag.FlagSet, choices ...string) {
+	o.format.choices = choices
+	fs.Var(&o.format, "format", "Output `format`: "+strings.Join(choices, ", "))
+}
+
+func (o *options) addFailOnFlag(fs *flag.FlagSet) {
+	fs.Var(&o.failOn, "fail-on", "Fail on findings of this `severity` or worse: error, warning or none")
+}
+
+func (o *options) addDaysFlag(fs *flag.FlagSet) {
+	fs.IntVar(&o.forecastDays, "days", 30, "Number of `days` to forecast, starting today")
+}
+
+// config checks the flag values and turns them into a config.
+func (o *options) config() (config, error) {
+	location, err := time.LoadLocation(o.tz)
+	if err != nil {
+		return config{}, fmt.Errorf("invalid -tz: %w", err)
+	}
+	cfg := config{
+		calendars:      o.calendars,
+		format:         o.format.value,
+		failOn:         o.failOn.value,
+		forecastDays:   o.forecastDays,
+		location:       location,
+		statePath:      o.statePath,
+		maxCatchUpDays: o.maxCatchUpDays,
+		dryRun:         o.dryRun,
+		rollback:       o.rollback,
+		endpoint:       o.endpoint,
+		retry:          defaultRetryPolicy,
+		timeout:        o.requestTimeout,
+		runTimeout:     o.timeout,
+	}
+	cfg.retry.maxAttempts = o.retries + 1
+	cfg.retry.maxDelay = o.maxRetryDelay
+	if o.proxy != "" {
+		proxyURL, err := url.Parse(o.proxy)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid -proxy: %w", err)
 		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyURL)
+		cfg.client = &http.Client{Transport: transport}
 	}
-	return retCode
+	if o.asOf != "" {
+		date, err := time.Parse("2006-01-02", o.asOf)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid -date: %w", err)
+		}
+		cfg.clock = dateClock(date)
+	}
+	return cfg, nil
+}
+
+// choiceFlag is a flag.Value that accepts one of a fixed set of strings.
+type choiceFlag struct {
+	value   string
+	choices []string
+}
+
+func (c *choiceFlag) String() string {
+	if c == nil {
+		return ""
+	}
+	return c.value
+}
+
+func (c *choiceFlag) Set(value string) error {
+	if !slices.Contains(c.choices, value) {
+		return fmt.Errorf("must be one of %s", strings.Join(c.choices, ", "))
+	}
+	c.value = value
+	return nil
+}
+
+func realMain() int {
+	return runCommandLine(os.Args[1:])
 }
 
 func main() {
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// runRepair sets up the sub-issue dependencies of an existing issue again,
+// creating the relations that are missing, for example after a run failed part
+// way or someone removed a relation by hand.
+func runRepair(ctx context.Context, token string, cfg config, issueRef string) int {
+	q := cfg.newQ(token)
+	issueID, err := getIssueID(ctx, q, issueRef)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to find issue %s: %v\n", issueRef, err)
+		return 1
+	}
+	fmt.Printf("Repairing sub-issue dependencies of %s\n", issueRef)
+	if err := setupSubIssueDependencies(ctx, q, issueID); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to repair %s: %v\n", issueRef, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// retryPolicy decides how q.do retries failed requests. Queries are retried on
+// network errors, 5xx responses, rate limiting and retryable GraphQL error
+// codes. Mutations are only retried when rate limited, since otherwise Linear
+// may have applied them before the failure.
+type retryPolicy struct {
+	maxAttempts int           // including the first; 0 or 1 means no retries
+	baseDelay   time.Duration // before the first retry; doubles with each one
+	maxDelay    time.Duration // longest wait; longer rate-limit resets fail instead
+	sleep       func(time.Duration)
+}
+
+var defaultRetryPolicy = retryPolicy{
+	maxAttempts: 5,
+	baseDelay:   500 * time.Millisecond,
+	maxDelay:    time.Minute,
+}
+
+// retryableCodes are GraphQL extensions.code values for failures that may go
+// away on their own. Any other code, such as AUTHENTICATION_ERROR or
+// INVALID_INPUT, is permanent.
+var retryableCodes = map[string]bool{
+	"RATELIMITED":           true,
+	"INTERNAL_SERVER_ERROR": true,
+	"SERVICE_UNAVAILABLE":   true,
+	"TIMEOUT":               true,
+}
+
+// retryableError is a failed attempt that may succeed if repeated.
+type retryableError struct {
+	err         error
+	rateLimited bool          // the request was rejected without being processed
+	wait        time.Duration // how long the server asked us to wait, if it did
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// backoff returns the delay before retry number attempt (starting at 1): the
+// server's requested wait if there is one, otherwise exponential backoff with
+// jitter between half and all of the nominal delay.
+func (p retryPolicy) backoff(attempt int, wait time.Duration) time.Duration {
+	if wait > 0 {
+		return wait
+	}
+	d := p.baseDelay << (attempt - 1)
+	if d > p.maxDelay || d <= 0 {
+		d = p.maxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// sleepFor waits for d, or until ctx is done, in which case it returns ctx's error.
+func (p retryPolicy) sleepFor(ctx context.Context, d time.Duration) error {
+	if p.sleep != nil {
+		p.sleep(d)
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// classifyResponse turns a response to operation op into a retryableError if
+// it is worth retrying, or another error if it failed permanently. GraphQL
+// errors in a successful response are left to q.query, unless they are
+// retryable.
+func classifyResponse(op string, resp *http.Response, body []byte) error {
+	wait := rateLimitWait(resp.Header, time.Now())
+	apiErr := parseAPIError(op, resp.StatusCode, body)
+	var err error = apiErr
+	if apiErr == nil {
+		err = fmt.Errorf("%s: non-200 response: %d, body: %s", op, resp.StatusCode, string(body))
+	}
+	code := apiErr.code()
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || code == "RATELIMITED":
+		return &retryableError{err: err, rateLimited: true, wait: wait}
+	case resp.StatusCode >= 500:
+		return &retryableError{err: err, wait: wait}
+	case resp.StatusCode != http.StatusOK:
+		return err
+	case retryableCodes[code]:
+		return &retryableError{err: err, wait: wait}
+	default:
+		return nil
+	}
+}
+
+// rateLimitWait returns how long the response headers ask us to wait: the
+// Retry-After header, or the time until the request or complexity budget
+// resets if it has run out. It returns 0 if there is no need to wait.
+func rateLimitWait(h http.Header, now time.Time) time.Duration {
+	if ra := h.Get("Retry-After"); ra != "" {
+		if secs, err := strconv.Atoi(ra); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+		if t, err := http.ParseTime(ra); err == nil {
+			return max(t.Sub(now), 0)
+		}
+	}
+	var wait time.Duration
+	for _, budget := range []string{"Requests", "Complexity"} {
+		if h.Get("X-RateLimit-"+budget+"-Remaining") != "0" {
+			continue
+		}
+		// Linear sends the reset time in milliseconds since the epoch.
+		ms, err := strconv.ParseInt(h.Get("X-RateLimit-"+budget+"-Reset"), 10, 64)
+		if err != nil {
+			continue
+		}
+		wait = max(wait, time.UnixMilli(ms).Sub(now))
+	}
+	return wait
+}
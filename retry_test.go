@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+// recordSleeps makes q's retries record their delays instead of sleeping.
+func recordSleeps(q *q) *[]time.Duration {
+	var sleeps []time.Duration
+	q.retry = retryPolicy{
+		maxAttempts: 4,
+		baseDelay:   100 * time.Millisecond,
+		maxDelay:    10 * time.Second,
+		sleep:       func(d time.Duration) { sleeps = append(sleeps, d) },
+	}
+	return &sleeps
+}
+
+func TestRetry_ServerErrorsThenSuccess(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	sleeps := recordSleeps(&q)
+	fake.failures = []fakeFailure{{status: http.StatusBadGateway}, {status: http.StatusServiceUnavailable}}
+
+	teamID, err := getTeamID(context.Background(), q, "Test Team")
+	assert.NoError(t, err)
+	assert.Equal(t, "team-1", teamID)
+	assert.Equal(t, 3, fake.requests)
+	assert.Equal(t, 2, len(*sleeps))
+	// Jittered between half and all of 100ms, then of 200ms.
+	assert.True(t, (*sleeps)[0] >= 50*time.Millisecond && (*sleeps)[0] <= 100*time.Millisecond)
+	assert.True(t, (*sleeps)[1] >= 100*time.Millisecond && (*sleeps)[1] <= 200*time.Millisecond)
+}
+
+func TestRetry_GivesUp(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	recordSleeps(&q)
+	for range 4 {
+		fake.failures = append(fake.failures, fakeFailure{status: http.StatusInternalServerError})
+	}
+
+	_, err := getTeamID(context.Background(), q, "Test Team")
+	assert.Error(t, err)
+	assert.Equal(t, 4, fake.requests)
+}
+
+func TestRetry_HonorsRetryAfter(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	sleeps := recordSleeps(&q)
+	fake.failures = []fakeFailure{{status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "3"}}}
+
+	_, err := getTeamID(context.Background(), q, "Test Team")
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{3 * time.Second}, *sleeps)
+}
+
+func TestRetry_RateLimitedGraphQLError(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	sleeps := recordSleeps(&q)
+	reset := time.Now().Add(2 * time.Second).UnixMilli()
+	fake.failures = []fakeFailure{{
+		status: http.StatusBadRequest,
+		code:   "RATELIMITED",
+		header: map[string]string{
+			"X-RateLimit-Requests-Remaining": "0",
+			"X-RateLimit-Requests-Reset":     strconv.FormatInt(reset, 10),
+		},
+	}}
+
+	// Mutations are retried when rate limited: the request was not processed.
+	fake.addTemplate("team-1", "T", "")
+	issueID, err := createIssueFromTemplate(context.Background(), q, "template-1", "team-1", time.Time{})
+	assert.NoError(t, err)
+	assert.NotEqual(t, "", issueID)
+	assert.Equal(t, 1, len(*sleeps))
+	assert.True(t, (*sleeps)[0] > time.Second && (*sleeps)[0] <= 2*time.Second)
+}
+
+func TestRetry_RateLimitResetTooFarAway(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	sleeps := recordSleeps(&q)
+	fake.failures = []fakeFailure{{status: http.StatusTooManyRequests, header: map[string]string{"Retry-After": "3600"}}}
+
+	_, err := getTeamID(context.Background(), q, "Test Team")
+	assert.Error(t, err)
+	assert.Equal(t, 0, len(*sleeps))
+}
+
+func TestRetry_MutationsNotRetriedOnServerError(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	recordSleeps(&q)
+	fake.addTemplate("team-1", "T", "")
+	fake.failures = []fakeFailure{{status: http.StatusBadGateway}}
+
+	_, err := createIssueFromTemplate(context.Background(), q, "template-1", "team-1", time.Time{})
+	assert.Error(t, err)
+	assert.Equal(t, 1, fake.requests)
+	assert.Equal(t, 0, len(fake.issuesFromTemplate("template-1")))
+}
+
+func TestRetry_PermanentErrorsNotRetried(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	recordSleeps(&q)
+	fake.failures = []fakeFailure{
+		{status: http.StatusBadRequest, code: "AUTHENTICATION_ERROR"},
+		{status: http.StatusOK, code: "FORBIDDEN"},
+	}
+
+	_, err := getTeamID(context.Background(), q, "Test Team")
+	assert.Error(t, err)
+	assert.Equal(t, 1, fake.requests)
+
+	_, err = getTemplates(context.Background(), q)
+	assert.Error(t, err)
+	assert.Equal(t, 2, fake.requests)
+}
+
+func TestRetry_WaitsWhenBudgetRunsOut(t *testing.T) {
+	h := http.Header{}
+	now := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Duration(0), rateLimitWait(h, now))
+
+	h.Set("X-RateLimit-Requests-Remaining", "10")
+	h.Set("X-RateLimit-Requests-Reset", strconv.FormatInt(now.Add(time.Minute).UnixMilli(), 10))
+	assert.Equal(t, time.Duration(0), rateLimitWait(h, now))
+
+	h.Set("X-RateLimit-Complexity-Remaining", "0")
+	h.Set("X-RateLimit-Complexity-Reset", strconv.FormatInt(now.Add(30*time.Second).UnixMilli(), 10))
+	assert.Equal(t, 30*time.Second, rateLimitWait(h, now))
+
+	h.Set("Retry-After", now.Add(5*time.Second).Format(http.TimeFormat))
+	assert.Equal(t, 5*time.Second, rateLimitWait(h, now))
+}
+
+func TestRetry_HungRequestTimesOut(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	sleeps := recordSleeps(&q)
+	q.timeout = 50 * time.Millisecond
+	fake.failures = []fakeFailure{{hang: true}}
+
+	teamID, err := getTeamID(context.Background(), q, "Test Team")
+	assert.NoError(t, err)
+	assert.Equal(t, "team-1", teamID)
+	assert.Equal(t, 2, fake.requests)
+	assert.Equal(t, 1, len(*sleeps))
+}
+
+func TestRetry_StopsWhenCancelled(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	q.retry = retryPolicy{
+		maxAttempts: 4,
+		baseDelay:   100 * time.Millisecond,
+		maxDelay:    10 * time.Second,
+		sleep:       func(time.Duration) { cancel() },
+	}
+	fake.failures = []fakeFailure{{status: http.StatusBadGateway}, {status: http.StatusBadGateway}}
+
+	_, err := getTeamID(ctx, q, "Test Team")
+	assert.IsError(t, err, context.Canceled)
+	assert.Equal(t, 1, fake.requests)
+}
@@ -0,0 +1,405 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type rruleFreq int
+
+const (
+	freqDaily rruleFreq = iota
+	freqWeekly
+	freqMonthly
+	freqYearly
+)
+
+var rruleFreqMap = map[string]rruleFreq{
+	"DAILY":   freqDaily,
+	"WEEKLY":  freqWeekly,
+	"MONTHLY": freqMonthly,
+	"YEARLY":  freqYearly,
+}
+
+var rruleWeekdayMap = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// rruleByDay is one BYDAY entry, such as TU (every Tuesday) or -1FR (last Friday).
+type rruleByDay struct {
+	weekday time.Weekday
+	nth     int // 0 for every occurrence in the period
+}
+
+// rrule is an RFC 5545 recurrence rule evaluated at day granularity. Time-of-day
+// parts (BYHOUR, BYMINUTE, BYSECOND and the time in DTSTART/UNTIL) are ignored.
+type rrule struct {
+	text       string
+	freq       rruleFreq
+	interval   int
+	byDay      []rruleByDay
+	byMonthDay []int // negative counts from the end of the month
+	byMonth    []time.Month
+	bySetPos   []int // negative counts from the end of the period
+	count      int
+	until      time.Time
+	dtstart    time.Time // zero if not given
+	wkst       time.Weekday
+}
+
+// parseRRule parses "RRULE:FREQ=...;..." optionally preceded by a
+// "DTSTART:YYYYMMDD" property. DTSTART may also be given inside the rule as
+// DTSTART=YYYYMMDD.
+func parseRRule(value string) (*rrule, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	r := &rrule{text: value, interval: 1, wkst: time.Monday}
+
+	rule, ok := strings.CutPrefix(value, "RRULE:")
+	if i := strings.Index(value, "RRULE:"); i > 0 && strings.HasPrefix(value, "DTSTART") {
+		// DTSTART[;params]:value as exported next to the RRULE property.
+		prop := strings.TrimRight(value[:i], " \t\n;")
+		d, err := parseICSDate(prop[strings.LastIndex(prop, ":")+1:])
+		if err != nil {
+			return nil, fmt.Errorf("DTSTART: %w", err)
+		}
+		r.dtstart = d
+		rule, ok = value[i+len("RRULE:"):], true
+	}
+	if !ok && strings.HasPrefix(value, "DTSTART") {
+		return nil, fmt.Errorf("expected RRULE: after DTSTART, on the same line or the next")
+	}
+	if !ok {
+		return nil, fmt.Errorf("expected RRULE:")
+	}
+
+	hasFreq := false
+	seen := map[string]bool{}
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s given twice", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			freq, ok := rruleFreqMap[val]
+			if !ok {
+				return nil, fmt.Errorf("unsupported FREQ %q", val)
+			}
+			r.freq = freq
+			hasFreq = true
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(val)
+			if err == nil && r.interval < 1 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(val)
+			if err == nil && r.count < 1 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "UNTIL":
+			r.until, err = parseICSDate(val)
+		case "DTSTART":
+			r.dtstart, err = parseICSDate(val)
+		case "WKST":
+			wd, ok := rruleWeekdayMap[val]
+			if !ok {
+				err = fmt.Errorf("invalid weekday")
+			}
+			r.wkst = wd
+		case "BYDAY":
+			r.byDay, err = parseRRuleByDay(val)
+		case "BYMONTHDAY":
+			r.byMonthDay, err = parseRRuleInts(val, 1, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseRRuleInts(val, 1, 12)
+			for _, m := range months {
+				if m < 0 {
+					err = fmt.Errorf("negative month %d", m)
+				}
+				r.byMonth = append(r.byMonth, time.Month(m))
+			}
+		case "BYSETPOS":
+			r.bySetPos, err = parseRRuleInts(val, 1, 366)
+		case "BYHOUR", "BYMINUTE", "BYSECOND":
+			// Issues are created per day.
+		default:
+			return nil, fmt.Errorf("unsupported rule part %s", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s=%s: %w", name, val, err)
+		}
+	}
+
+	if !hasFreq {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if r.count > 0 && !r.until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot both be given")
+	}
+	if r.freq == freqDaily || r.freq == freqWeekly {
+		for _, bd := range r.byDay {
+			if bd.nth != 0 {
+				return nil, fmt.Errorf("BYDAY ordinals need FREQ=MONTHLY or FREQ=YEARLY")
+			}
+		}
+	}
+	if r.dtstart.IsZero() {
+		switch {
+		case r.count > 0:
+			return nil, fmt.Errorf("COUNT needs DTSTART")
+		case r.interval > 1:
+			return nil, fmt.Errorf("INTERVAL needs DTSTART")
+		case r.needsStartDefaults():
+			return nil, fmt.Errorf("DTSTART is needed to know which days the rule falls on")
+		}
+	}
+	return r, nil
+}
+
+func parseRRuleByDay(val string) ([]rruleByDay, error) {
+	var out []rruleByDay
+	for _, item := range strings.Split(val, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid day %q", item)
+		}
+		wd, ok := rruleWeekdayMap[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", item)
+		}
+		bd := rruleByDay{weekday: wd}
+		if ord := item[:len(item)-2]; ord != "" {
+			n, err := strconv.Atoi(ord)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid ordinal in %q", item)
+			}
+			bd.nth = n
+		}
+		out = append(out, bd)
+	}
+	return out, nil
+}
+
+// parseRRuleInts parses a list of integers whose absolute values are within lo and hi.
+func parseRRuleInts(val string, lo, hi int) ([]int, error) {
+	var out []int
+	for _, item := range strings.Split(val, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", item)
+		}
+		if abs := max(n, -n); abs < lo || abs > hi {
+			return nil, fmt.Errorf("%d out of range", n)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// needsStartDefaults reports whether the rule takes its days from DTSTART
+// because its BY* parts don't pick days within each period.
+func (r *rrule) needsStartDefaults() bool {
+	switch r.freq {
+	case freqWeekly:
+		return len(r.byDay) == 0
+	case freqMonthly, freqYearly:
+		return len(r.byDay) == 0 && len(r.byMonthDay) == 0
+	default:
+		return false
+	}
+}
+
+func (r *rrule) matches(date time.Time) bool {
+	if !r.dtstart.IsZero() && daysBetween(r.dtstart, date) < 0 {
+		return false
+	}
+	if !r.until.IsZero() && daysBetween(r.until, date) > 0 {
+		return false
+	}
+	if r.count > 0 {
+		return r.countMatches(date)
+	}
+	if !r.dtstart.IsZero() && r.periodsBetween(r.dtstart, date)%r.interval != 0 {
+		return false
+	}
+	return slices.ContainsFunc(r.periodOccurrences(r.periodStart(date)), func(d time.Time) bool {
+		return daysBetween(d, date) == 0
+	})
+}
+
+// occurrences returns the rule's days from DTSTART on, up to and including
+// through, or up to where COUNT or UNTIL ends the rule if that is earlier.
+func (r *rrule) occurrences(through time.Time) []time.Time {
+	var days []time.Time
+	for start := r.periodStart(r.dtstart); daysBetween(start, through) >= 0; start = r.addPeriods(start, r.interval) {
+		for _, d := range r.periodOccurrences(start) {
+			if daysBetween(r.dtstart, d) < 0 {
+				continue
+			}
+			if daysBetween(d, through) < 0 || !r.until.IsZero() && daysBetween(r.until, d) > 0 {
+				return days
+			}
+			days = append(days, d)
+			if len(days) == r.count {
+				return days
+			}
+		}
+	}
+	return days
+}
+
+// countMatches enumerates occurrences from DTSTART to check whether date is among the first COUNT.
+func (r *rrule) countMatches(date time.Time) bool {
+	n := 0
+	for start := r.periodStart(r.dtstart); daysBetween(start, date) >= 0; start = r.addPeriods(start, r.interval) {
+		for _, d := range r.periodOccurrences(start) {
+			if daysBetween(r.dtstart, d) < 0 {
+				continue
+			}
+			n++
+			if daysBetween(d, date) == 0 {
+				return true
+			}
+			if n >= r.count || daysBetween(d, date) < 0 {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// periodStart returns the first day of the FREQ period containing date.
+func (r *rrule) periodStart(date time.Time) time.Time {
+	d := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	switch r.freq {
+	case freqWeekly:
+		return d.AddDate(0, 0, -((int(d.Weekday()) - int(r.wkst) + 7) % 7))
+	case freqMonthly:
+		return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case freqYearly:
+		return time.Date(d.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return d
+	}
+}
+
+func (r *rrule) addPeriods(start time.Time, n int) time.Time {
+	switch r.freq {
+	case freqWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case freqMonthly:
+		return start.AddDate(0, n, 0)
+	case freqYearly:
+		return start.AddDate(n, 0, 0)
+	default:
+		return start.AddDate(0, 0, n)
+	}
+}
+
+// periodsBetween returns how many FREQ periods after from's period date's period is.
+func (r *rrule) periodsBetween(from, date time.Time) int {
+	switch r.freq {
+	case freqWeekly:
+		return daysBetween(r.periodStart(from), r.periodStart(date)) / 7
+	case freqMonthly:
+		return (date.Year()-from.Year())*12 + int(date.Month()-from.Month())
+	case freqYearly:
+		return date.Year() - from.Year()
+	default:
+		return daysBetween(from, date)
+	}
+}
+
+// periodOccurrences returns the days in the period starting at start that the
+// BY* parts select, in order, after applying BYSETPOS.
+func (r *rrule) periodOccurrences(start time.Time) []time.Time {
+	end := r.addPeriods(start, 1)
+	var days []time.Time
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		if r.dayMatches(d) {
+			days = append(days, d)
+		}
+	}
+	if len(r.bySetPos) == 0 {
+		return days
+	}
+	var picked []time.Time
+	for _, pos := range r.bySetPos {
+		i := pos - 1
+		if pos < 0 {
+			i = len(days) + pos
+		}
+		if i >= 0 && i < len(days) && !slices.Contains(picked, days[i]) {
+			picked = append(picked, days[i])
+		}
+	}
+	slices.SortFunc(picked, func(a, b time.Time) int { return a.Compare(b) })
+	return picked
+}
+
+func (r *rrule) dayMatches(d time.Time) bool {
+	if len(r.byMonth) > 0 && !slices.Contains(r.byMonth, d.Month()) {
+		return false
+	}
+	if len(r.byMonthDay) > 0 && !slices.ContainsFunc(r.byMonthDay, func(md int) bool {
+		if md < 0 {
+			md = lastDayOfMonth(d) + 1 + md
+		}
+		return d.Day() == md
+	}) {
+		return false
+	}
+	if len(r.byDay) > 0 && !slices.ContainsFunc(r.byDay, func(bd rruleByDay) bool {
+		return d.Weekday() == bd.weekday && (bd.nth == 0 || r.nthInPeriod(d, bd.nth))
+	}) {
+		return false
+	}
+
+	// Without BY* parts picking days, the rule repeats DTSTART's day.
+	switch r.freq {
+	case freqWeekly:
+		return len(r.byDay) > 0 || d.Weekday() == r.dtstart.Weekday()
+	case freqMonthly:
+		return !r.needsStartDefaults() || d.Day() == r.dtstart.Day()
+	case freqYearly:
+		if !r.needsStartDefaults() {
+			return true
+		}
+		return d.Day() == r.dtstart.Day() && (len(r.byMonth) > 0 || d.Month() == r.dtstart.Month())
+	default:
+		return true
+	}
+}
+
+// nthInPeriod reports whether d is the nth occurrence of its weekday within its
+// month, or within its year for FREQ=YEARLY without BYMONTH. Negative n counts from the end.
+func (r *rrule) nthInPeriod(d time.Time, n int) bool {
+	pos, length := d.Day(), lastDayOfMonth(d)
+	if r.freq == freqYearly && len(r.byMonth) == 0 {
+		pos = d.YearDay()
+		length = time.Date(d.Year(), time.December, 31, 0, 0, 0, 0, time.UTC).YearDay()
+	}
+	if n > 0 {
+		return (pos-1)/7+1 == n
+	}
+	return (length-pos)/7+1 == -n
+}
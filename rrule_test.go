@@ -0,0 +1,135 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func rruleDates(t *testing.T, rule string, from time.Time, days int) []time.Time {
+	t.Helper()
+	ts := parseTemplateSchedule("Recurrence: "+rule, nil)
+	assert.Equal(t, 1, len(ts.schedules))
+	assert.Equal(t, scheduleRRule, ts.schedules[0].kind, formatSchedule(ts.schedules[0]))
+	return nextTriggerDates(ts, from, days, 100)
+}
+
+func TestRRule_MonthlyNthWeekdayInMonths(t *testing.T) {
+	dates := rruleDates(t, "RRULE:FREQ=MONTHLY;BYDAY=2TU;BYMONTH=1,4,7,10", date(2026, time.January, 1), 365)
+	assert.Equal(t, []time.Time{
+		date(2026, time.January, 13),
+		date(2026, time.April, 14),
+		date(2026, time.July, 14),
+		date(2026, time.October, 13),
+	}, dates)
+}
+
+func TestRRule_WeeklyIntervalFromDTStart(t *testing.T) {
+	dates := rruleDates(t, "DTSTART:20260105T090000Z RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", date(2026, time.January, 1), 28)
+	assert.Equal(t, []time.Time{
+		date(2026, time.January, 5),
+		date(2026, time.January, 8),
+		date(2026, time.January, 19),
+		date(2026, time.January, 22),
+	}, dates)
+}
+
+func TestRRule_WeeklyDefaultsToDTStartWeekday(t *testing.T) {
+	// 2026-01-07 is a Wednesday.
+	dates := rruleDates(t, "RRULE:FREQ=WEEKLY;DTSTART=20260107;COUNT=3", date(2026, time.January, 1), 60)
+	assert.Equal(t, []time.Time{
+		date(2026, time.January, 7),
+		date(2026, time.January, 14),
+		date(2026, time.January, 21),
+	}, dates)
+}
+
+func TestRRule_NegativeMonthDay(t *testing.T) {
+	dates := rruleDates(t, "RRULE:FREQ=MONTHLY;BYMONTHDAY=-2", date(2026, time.January, 1), 90)
+	assert.Equal(t, []time.Time{
+		date(2026, time.January, 30),
+		date(2026, time.February, 27),
+		date(2026, time.March, 30),
+	}, dates)
+}
+
+func TestRRule_LastWorkdayOfMonth(t *testing.T) {
+	dates := rruleDates(t, "RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", date(2026, time.January, 1), 120)
+	assert.Equal(t, []time.Time{
+		date(2026, time.January, 30),
+		date(2026, time.February, 27),
+		date(2026, time.March, 31),
+		date(2026, time.April, 30),
+	}, dates)
+}
+
+func TestRRule_YearlyNthWeekdayOfYear(t *testing.T) {
+	// The 20th Monday of the year, and the last Friday of the year.
+	dates := rruleDates(t, "RRULE:FREQ=YEARLY;BYDAY=20MO,-1FR", date(2026, time.January, 1), 365)
+	assert.Equal(t, []time.Time{
+		date(2026, time.May, 18),
+		date(2026, time.December, 25),
+	}, dates)
+}
+
+func TestRRule_YearlyDefaultsToDTStart(t *testing.T) {
+	dates := rruleDates(t, "RRULE:FREQ=YEARLY;DTSTART=20240229", date(2026, time.January, 1), 3*366)
+	assert.Equal(t, []time.Time{date(2028, time.February, 29)}, dates)
+}
+
+func TestRRule_Until(t *testing.T) {
+	dates := rruleDates(t, "RRULE:FREQ=DAILY;UNTIL=20260103T235959Z", date(2026, time.January, 1), 10)
+	assert.Equal(t, []time.Time{
+		date(2026, time.January, 1),
+		date(2026, time.January, 2),
+		date(2026, time.January, 3),
+	}, dates)
+}
+
+func TestRRule_CountWithSetPos(t *testing.T) {
+	// First weekday of each quarter, four times.
+	dates := rruleDates(t, "RRULE:FREQ=MONTHLY;INTERVAL=3;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1;COUNT=4;DTSTART=20260101", date(2026, time.January, 1), 730)
+	assert.Equal(t, []time.Time{
+		date(2026, time.January, 1),
+		date(2026, time.April, 1),
+		date(2026, time.July, 1),
+		date(2026, time.October, 1),
+	}, dates)
+}
+
+func TestRRule_ExportedOnTwoLines(t *testing.T) {
+	ts := parseTemplateSchedule("Recurrence: DTSTART;TZID=Europe/Berlin:20260105T090000\nRRULE:FREQ=WEEKLY;INTERVAL=2\nTime: 09:00", nil)
+	assert.Equal(t, 1, len(ts.schedules))
+	assert.Equal(t, scheduleRRule, ts.schedules[0].kind, formatSchedule(ts.schedules[0]))
+	assert.Equal(t, []time.Time{date(2026, time.January, 5), date(2026, time.January, 19)}, nextTriggerDates(ts, date(2026, time.January, 1), 28, 10))
+
+	// Without a DTSTART line before it, an RRULE: line is reported, not ignored.
+	ts = parseTemplateSchedule("Recurrence: Mon\nRRULE:FREQ=WEEKLY", nil)
+	assert.Equal(t, 2, len(ts.schedules))
+	assert.Equal(t, scheduleMalformed, ts.schedules[1].kind)
+
+	ts = parseTemplateSchedule("Recurrence: DTSTART:20260105\nTime: 09:00", nil)
+	assert.Equal(t, scheduleMalformed, ts.schedules[0].kind)
+	assert.Contains(t, ts.schedules[0].problem, "on the same line or the next")
+}
+
+func TestRRule_Errors(t *testing.T) {
+	for _, rule := range []string{
+		"RRULE:INTERVAL=2",
+		"RRULE:FREQ=HOURLY",
+		"RRULE:FREQ=DAILY;COUNT=3;UNTIL=20260101",
+		"RRULE:FREQ=WEEKLY;BYDAY=2TU",
+		"RRULE:FREQ=WEEKLY",
+		"RRULE:FREQ=DAILY;INTERVAL=2",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=32",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=0",
+		"RRULE:FREQ=YEARLY;BYWEEKNO=20",
+		"RRULE:FREQ=DAILY;FREQ=WEEKLY",
+		"DTSTART:2026 RRULE:FREQ=DAILY",
+	} {
+		s := parseRecurrence(rule, rule)
+		assert.Equal(t, scheduleMalformed, s.kind, rule)
+		assert.NotEqual(t, "", s.problem, rule)
+	}
+}
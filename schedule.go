@@ -1,6 +1,7 @@
 package main
 
 import (
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +17,21 @@ var weekdayMap = map[string]time.Weekday{
 	"sun": time.Sunday,
 }
 
+// ordinalMap maps an ordinal to the occurrence of a weekday within a month; -1 is the last one.
+var ordinalMap = map[string]int{
+	"1st":    1,
+	"2nd":    2,
+	"3rd":    3,
+	"4th":    4,
+	"5th":    5,
+	"first":  1,
+	"second": 2,
+	"third":  3,
+	"fourth": 4,
+	"fifth":  5,
+	"last":   -1,
+}
+
 var monthMap = map[string]time.Month{
 	"jan": time.January,
 	"feb": time.February,
@@ -41,16 +57,44 @@ const (
 	scheduleMonthDay
 	scheduleMonthLast
 	scheduleAt
+	scheduleEvery
+	scheduleNthWeekday
+	scheduleMonthNthWeekday
+	scheduleCron
+	scheduleRRule
 	scheduleMalformed
 )
 
+type intervalUnit int
+
+const (
+	unitDay intervalUnit = iota
+	unitWeek
+	unitMonth
+)
+
+var intervalUnitMap = map[string]intervalUnit{
+	"day":    unitDay,
+	"days":   unitDay,
+	"week":   unitWeek,
+	"weeks":  unitWeek,
+	"month":  unitMonth,
+	"months": unitMonth,
+}
+
 type schedule struct {
-	kind    scheduleKind
-	weekday time.Weekday // scheduleWeekday
-	day     int          // scheduleDayOfMonth, scheduleMonthDay
-	month   time.Month   // scheduleMonthDay, scheduleMonthLast
-	date    time.Time    // scheduleAt
-	raw     string       // scheduleMalformed
+	kind     scheduleKind
+	weekday  time.Weekday // scheduleWeekday, scheduleNthWeekday, scheduleMonthNthWeekday
+	nth      int          // scheduleNthWeekday, scheduleMonthNthWeekday; -1 for last
+	day      int          // scheduleDayOfMonth, scheduleMonthDay
+	month    time.Month   // scheduleMonthDay, scheduleMonthLast, scheduleMonthNthWeekday
+	date     time.Time    // scheduleAt, scheduleEvery (anchor)
+	interval int          // scheduleEvery
+	unit     intervalUnit // scheduleEvery
+	cron     *cronSpec    // scheduleCron
+	rrule    *rrule       // scheduleRRule
+	raw      string       // scheduleMalformed
+	problem  string       // scheduleMalformed, if there is more to say than the line itself
 }
 
 func (s schedule) matches(date time.Time) bool {
@@ -69,25 +113,321 @@ func (s schedule) matches(date time.Time) bool {
 		return date.Month() == s.month && date.Day() == lastDayOfMonth(date)
 	case scheduleAt:
 		return date.Equal(s.date)
+	case scheduleEvery:
+		return s.matchesEvery(date)
+	case scheduleNthWeekday:
+		return date.Weekday() == s.weekday && nthWeekdayMatches(date, s.nth)
+	case scheduleMonthNthWeekday:
+		return date.Month() == s.month && date.Weekday() == s.weekday && nthWeekdayMatches(date, s.nth)
+	case scheduleCron:
+		return s.cron.matches(date)
+	case scheduleRRule:
+		return s.rrule.matches(date)
 	default:
 		return false
 	}
 }
 
-// parseSchedules extracts all valid schedule entries from a template description.
-func parseSchedules(description string) []schedule {
-	var schedules []schedule
-	for _, line := range strings.Split(description, "|") {
-		line = strings.TrimSpace(line)
+// nthWeekdayMatches reports whether date is the nth occurrence of its weekday in its month.
+func nthWeekdayMatches(date time.Time, nth int) bool {
+	if nth == -1 {
+		return date.Day()+7 > lastDayOfMonth(date)
+	}
+	return (date.Day()-1)/7+1 == nth
+}
+
+// matchesEvery reports whether date is a whole number of intervals after the anchor.
+// Monthly intervals anchored past the end of a shorter month fire on its last day.
+func (s schedule) matchesEvery(date time.Time) bool {
+	days := daysBetween(s.date, date)
+	if days < 0 {
+		return false
+	}
+	switch s.unit {
+	case unitDay:
+		return days%s.interval == 0
+	case unitWeek:
+		return days%(7*s.interval) == 0
+	case unitMonth:
+		months := (date.Year()-s.date.Year())*12 + int(date.Month()-s.date.Month())
+		if months%s.interval != 0 {
+			return false
+		}
+		return date.Day() == min(s.date.Day(), lastDayOfMonth(date))
+	default:
+		return false
+	}
+}
+
+type adjustment int
+
+const (
+	adjustNone adjustment = iota
+	adjustNextBusinessDay
+	adjustPreviousBusinessDay
+)
+
+var adjustmentMap = map[string]adjustment{
+	"none":                  adjustNone,
+	"next-business-day":     adjustNextBusinessDay,
+	"previous-business-day": adjustPreviousBusinessDay,
+}
+
+// catchUpPolicy says what to do about occurrences missed while no run happened.
+type catchUpPolicy int
+
+const (
+	catchUpLatest catchUpPolicy = iota // one issue for the missed occurrences and today together
+	catchUpSkip                        // only ever create today's issue
+	catchUpAll                         // one issue per occurrence
+)
+
+var catchUpPolicyMap = map[string]catchUpPolicy{
+	"latest": catchUpLatest,
+	"skip":   catchUpSkip,
+	"all":    catchUpAll,
+}
+
+// templateSchedule is everything a template description says about when it
+// fires: the OR'd schedule lines plus modifiers that apply to all of them.
+type templateSchedule struct {
+	schedules []schedule
+	adjust    adjustment
+	holidays  calendar       // not business days
+	except    calendar       // never fires
+	from      time.Time      // first possible date, zero if unbounded
+	until     time.Time      // last possible date, zero if unbounded
+	count     int            // maximum number of occurrences counted from from, 0 if unlimited
+	counted   *countScan     // how far occurrences have been counted, nil without count
+	location  *time.Location // nil to use the default time zone
+	timeOfDay time.Duration  // how long after local midnight the template becomes due
+	catchUp   catchUpPolicy
+}
+
+// countScan is how far the occurrences of a template with a Count: limit have
+// been counted. Counting only goes as far as the dates asked about and picks up
+// where it stopped; copies of a templateSchedule share it.
+type countScan struct {
+	next time.Time // first day not counted yet
+	n    int       // occurrences before next
+	end  time.Time // date of the count-th occurrence, zero if not reached yet
+}
+
+// countEndLookahead is how many days after From: end looks for the last
+// occurrence of a Count: limit. Bounds checks don't depend on it.
+const countEndLookahead = 10 * 366
+
+// parseTemplateSchedule extracts the schedule lines and modifiers from a template description.
+// Holidays: and Except: lines may name calendars, which are looked up in calendars.
+// Malformed modifier lines are reported as scheduleMalformed entries alongside the schedules.
+func parseTemplateSchedule(description string, calendars map[string]calendar) templateSchedule {
+	ts := templateSchedule{holidays: calendar{}, except: calendar{}}
+	countLine := ""
+	timeSet := false
+	lines := descriptionLines(description)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
 		lower := strings.ToLower(line)
 
 		if after, ok := strings.CutPrefix(lower, "recurrence:"); ok {
-			schedules = append(schedules, parseRecurrence(strings.TrimSpace(after), line))
+			value := strings.TrimSpace(after)
+			// Calendar apps export DTSTART and RRULE on lines of their own.
+			if strings.HasPrefix(value, "dtstart") && !strings.Contains(value, "rrule:") &&
+				i+1 < len(lines) && strings.HasPrefix(strings.ToLower(lines[i+1]), "rrule:") {
+				i++
+				value += " rrule:" + strings.TrimSpace(strings.ToLower(lines[i])[len("rrule:"):])
+				line += " " + lines[i]
+			}
+			ts.schedules = append(ts.schedules, parseRecurrence(value, line))
+		} else if strings.HasPrefix(lower, "rrule:") {
+			ts.schedules = append(ts.schedules, schedule{kind: scheduleMalformed, raw: line, problem: "RRULE: goes on a Recurrence: line, or on the line after Recurrence: DTSTART..."})
 		} else if after, ok := strings.CutPrefix(lower, "at:"); ok {
-			schedules = append(schedules, parseAt(strings.TrimSpace(after), line))
+			ts.schedules = append(ts.schedules, parseAt(strings.TrimSpace(after), line))
+		} else if after, ok := strings.CutPrefix(lower, "adjust:"); ok {
+			adjust, ok := adjustmentMap[strings.TrimSpace(after)]
+			if !ok {
+				ts.schedules = append(ts.schedules, schedule{kind: scheduleMalformed, raw: line})
+				continue
+			}
+			ts.adjust = adjust
+		} else if after, ok := strings.CutPrefix(lower, "holidays:"); ok {
+			if !parseDateRefs(after, calendars, ts.holidays) {
+				ts.schedules = append(ts.schedules, schedule{kind: scheduleMalformed, raw: line})
+			}
+		} else if after, ok := strings.CutPrefix(lower, "except:"); ok {
+			if !parseDateRefs(after, calendars, ts.except) {
+				ts.schedules = append(ts.schedules, schedule{kind: scheduleMalformed, raw: line})
+			}
+		} else if after, ok := strings.CutPrefix(lower, "from:"); ok {
+			d, err := time.Parse("2006-01-02", strings.TrimSpace(after))
+			if err != nil {
+				ts.schedules = append(ts.schedules, schedule{kind: scheduleMalformed, raw: line})
+				continue
+			}
+			ts.from = d
+		} else if after, ok := strings.CutPrefix(lower, "until:"); ok {
+			d, err := time.Parse("2006-01-02", strings.TrimSpace(after))
+			if err != nil {
+				ts.schedules = append(ts.schedules, schedule{kind: scheduleMalformed, raw: line})
+				continue
+			}
+			ts.until = d
+		} else if after, ok := strings.CutPrefix(lower, "count:"); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(after))
+			if err != nil || n < 1 {
+				ts.schedules = append(ts.schedules, schedule{kind: scheduleMalformed, raw: line})
+				continue
+			}
+			ts.count = n
+			countLine = line
+		} else if strings.HasPrefix(lower, "timezone:") {
+			// Zone names are case-sensitive, so take the value from the original line.
+			loc, err := time.LoadLocation(strings.TrimSpace(line[len("timezone:"):]))
+			if err != nil {
+				ts.schedules = append(ts.schedules, schedule{kind: scheduleMalformed, raw: line, problem: err.Error()})
+				continue
+			}
+			ts.location = loc
+		} else if after, ok := strings.CutPrefix(lower, "catchup:"); ok {
+			policy, ok := catchUpPolicyMap[strings.TrimSpace(after)]
+			if !ok {
+				ts.schedules = append(ts.schedules, schedule{kind: scheduleMalformed, raw: line})
+				continue
+			}
+			ts.catchUp = policy
+		} else if after, ok := strings.CutPrefix(lower, "time:"); ok {
+			t, err := time.Parse("15:04", strings.TrimSpace(after))
+			if err != nil {
+				ts.schedules = append(ts.schedules, schedule{kind: scheduleMalformed, raw: line})
+				continue
+			}
+			ts.timeOfDay = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+			timeSet = true
+		}
+	}
+	if !timeSet {
+		// Without a Time: line, the first cron line with a single time of day sets it.
+		for _, s := range ts.schedules {
+			if s.kind != scheduleCron {
+				continue
+			}
+			if tod, ok := s.cron.timeOfDay(); ok {
+				ts.timeOfDay = tod
+				break
+			}
+		}
+	}
+	if ts.count > 0 {
+		// Counting needs a starting point.
+		if ts.from.IsZero() {
+			ts.schedules = append(ts.schedules, schedule{kind: scheduleMalformed, raw: countLine})
+			ts.count = 0
+		} else {
+			ts.counted = &countScan{next: ts.from}
+		}
+	}
+	return ts
+}
+
+// countEndBy returns the date of the count-th occurrence on or after from if
+// it is on or before date, or else the zero time. Days after Until: are not
+// counted.
+func (ts templateSchedule) countEndBy(date time.Time) time.Time {
+	c := ts.counted
+	if c == nil {
+		return time.Time{}
+	}
+	for c.end.IsZero() && daysBetween(c.next, date) >= 0 {
+		if !ts.until.IsZero() && daysBetween(ts.until, c.next) > 0 {
+			break
+		}
+		if ts.adjusted(c.next) && !ts.excepted(c.next) {
+			c.n++
+			if c.n == ts.count {
+				c.end = c.next
+			}
 		}
+		c.next = c.next.AddDate(0, 0, 1)
 	}
-	return schedules
+	if c.end.IsZero() || daysBetween(c.end, date) < 0 {
+		return time.Time{}
+	}
+	return c.end
+}
+
+// countEnd returns the date of the count-th occurrence, or the zero time if
+// there is none within countEndLookahead days of from.
+func (ts templateSchedule) countEnd() time.Time {
+	return ts.countEndBy(ts.from.AddDate(0, 0, countEndLookahead))
+}
+
+var (
+	// markdownPrefixRx matches block markup before a line's text: quotes,
+	// headings, list bullets and numbers, and task checkboxes.
+	markdownPrefixRx = regexp.MustCompile(`^(?:>\s*)*(?:#{1,6}\s+)?(?:(?:[-*+]|\d+[.)])\s+)?(?:\[[ xX]\]\s+)?`)
+	// markdownKeyRx matches an emphasized key such as **Recurrence:** or *At*:.
+	markdownKeyRx = regexp.MustCompile(`^[*_]+([A-Za-z]+)[*_]*:[*_]*`)
+	// markdownEscapeRx matches a backslash-escaped punctuation character.
+	markdownEscapeRx = regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])")
+)
+
+// descriptionLines splits a template description into lines that may hold
+// schedule entries. Lines are separated by newlines or "|", and the markdown
+// that Linear wraps descriptions in is removed.
+func descriptionLines(description string) []string {
+	var lines []string
+	for _, line := range strings.FieldsFunc(description, func(r rune) bool { return r == '\n' || r == '|' }) {
+		line = strings.ReplaceAll(line, "\u00a0", " ")
+		line = strings.TrimSpace(line)
+		line = strings.TrimSuffix(line, "\\") // hard line break
+		line = markdownEscapeRx.ReplaceAllString(line, "$1")
+		line = strings.ReplaceAll(line, "`", "")
+		line = markdownPrefixRx.ReplaceAllString(line, "")
+		line = markdownKeyRx.ReplaceAllString(line, "$1:")
+		key, value, ok := strings.Cut(line, ":")
+		if ok {
+			value = strings.TrimSpace(value)
+			for _, em := range []string{"**", "__", "*", "_"} {
+				if len(value) > 2*len(em) && strings.HasPrefix(value, em) && strings.HasSuffix(value, em) {
+					value = value[len(em) : len(value)-len(em)]
+					break
+				}
+			}
+			line = key + ": " + value
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	return lines
+}
+
+// parseSchedules extracts all schedule entries from a template description.
+func parseSchedules(description string) []schedule {
+	return parseTemplateSchedule(description, nil).schedules
+}
+
+// parseDateRefs adds a comma- or space-separated list of dates and calendar
+// names to into. It adds nothing and returns false if any entry is neither a
+// valid date nor a known calendar.
+func parseDateRefs(value string, calendars map[string]calendar, into calendar) bool {
+	fields := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' })
+	var dates []string
+	for _, field := range fields {
+		if cal, ok := calendars[field]; ok {
+			for d := range cal {
+				dates = append(dates, d)
+			}
+			continue
+		}
+		d, err := time.Parse("2006-01-02", field)
+		if err != nil {
+			return false
+		}
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+	for _, d := range dates {
+		into[d] = true
+	}
+	return len(fields) > 0
 }
 
 func parseRecurrence(value string, rawLine string) schedule {
@@ -109,7 +449,40 @@ func parseRecurrence(value string, rawLine string) schedule {
 		return schedule{kind: scheduleDayOfMonth, day: day}
 	}
 
+	if expr, ok := strings.CutPrefix(lower, "cron "); ok {
+		spec, err := parseCron(expr)
+		if err != nil {
+			return schedule{kind: scheduleMalformed, raw: rawLine, problem: err.Error()}
+		}
+		return schedule{kind: scheduleCron, cron: spec}
+	}
+
+	if strings.HasPrefix(lower, "rrule:") || strings.HasPrefix(lower, "dtstart") {
+		r, err := parseRRule(value)
+		if err != nil {
+			return schedule{kind: scheduleMalformed, raw: rawLine, problem: err.Error()}
+		}
+		return schedule{kind: scheduleRRule, rrule: r}
+	}
+
 	parts := strings.Fields(lower)
+	if len(parts) > 0 && parts[0] == "every" {
+		return parseEvery(parts[1:], rawLine)
+	}
+	if (len(parts) == 2 || len(parts) == 4 && parts[2] == "of") && ordinalMap[parts[0]] != 0 {
+		wd, ok := weekdayMap[parts[1]]
+		if !ok {
+			return schedule{kind: scheduleMalformed, raw: rawLine}
+		}
+		if len(parts) == 2 {
+			return schedule{kind: scheduleNthWeekday, nth: ordinalMap[parts[0]], weekday: wd}
+		}
+		month, ok := monthMap[parts[3]]
+		if !ok {
+			return schedule{kind: scheduleMalformed, raw: rawLine}
+		}
+		return schedule{kind: scheduleMonthNthWeekday, nth: ordinalMap[parts[0]], weekday: wd, month: month}
+	}
 	if len(parts) == 2 {
 		month, ok := monthMap[parts[0]]
 		if ok {
@@ -125,6 +498,31 @@ func parseRecurrence(value string, rawLine string) schedule {
 	return schedule{kind: scheduleMalformed, raw: rawLine}
 }
 
+// parseEvery parses the part of "every [N] day(s)|week(s)|month(s) from YYYY-MM-DD" after "every".
+func parseEvery(parts []string, rawLine string) schedule {
+	interval := 1
+	if len(parts) == 4 {
+		n, err := strconv.Atoi(parts[0])
+		if err != nil || n < 1 {
+			return schedule{kind: scheduleMalformed, raw: rawLine}
+		}
+		interval = n
+		parts = parts[1:]
+	}
+	if len(parts) != 3 || parts[1] != "from" {
+		return schedule{kind: scheduleMalformed, raw: rawLine}
+	}
+	unit, ok := intervalUnitMap[parts[0]]
+	if !ok {
+		return schedule{kind: scheduleMalformed, raw: rawLine}
+	}
+	anchor, err := time.Parse("2006-01-02", parts[2])
+	if err != nil {
+		return schedule{kind: scheduleMalformed, raw: rawLine}
+	}
+	return schedule{kind: scheduleEvery, interval: interval, unit: unit, date: anchor}
+}
+
 func parseAt(value string, rawLine string) schedule {
 	t, err := time.Parse("2006-01-02", value)
 	if err != nil {
@@ -133,8 +531,35 @@ func parseAt(value string, rawLine string) schedule {
 	return schedule{kind: scheduleAt, date: t}
 }
 
-func templateMatchesSchedule(description string, date time.Time) bool {
-	for _, s := range parseSchedules(description) {
+// locationOr returns the template's time zone, or def if it doesn't set one.
+func (ts templateSchedule) locationOr(def *time.Location) *time.Location {
+	if ts.location != nil {
+		return ts.location
+	}
+	return def
+}
+
+// today returns the template's calendar day at now, in its time zone or def.
+func (ts templateSchedule) today(now time.Time, def *time.Location) time.Time {
+	return civilDate(now.In(ts.locationOr(def)))
+}
+
+// dayStart returns the instant the template's calendar day at now began.
+func (ts templateSchedule) dayStart(now time.Time, def *time.Location) time.Time {
+	local := now.In(ts.locationOr(def))
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+}
+
+// timeReached reports whether the template's time of day has passed at now.
+func (ts templateSchedule) timeReached(now time.Time, def *time.Location) bool {
+	local := now.In(ts.locationOr(def))
+	wall := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	return wall >= ts.timeOfDay
+}
+
+// scheduled reports whether any schedule line matches date, before adjustment.
+func (ts templateSchedule) scheduled(date time.Time) bool {
+	for _, s := range ts.schedules {
 		if s.matches(date) {
 			return true
 		}
@@ -142,6 +567,116 @@ func templateMatchesSchedule(description string, date time.Time) bool {
 	return false
 }
 
+func (ts templateSchedule) isBusinessDay(date time.Time) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+	return !ts.holidays.contains(date)
+}
+
+// matches reports whether the template fires on date: it is due and not excepted.
+func (ts templateSchedule) matches(date time.Time) bool {
+	return ts.due(date) && !ts.excepted(date)
+}
+
+// excepted reports whether an Except: line suppresses the template on date.
+func (ts templateSchedule) excepted(date time.Time) bool {
+	return ts.except.contains(date)
+}
+
+// due reports whether the schedule puts the template on date, ignoring Except: lines.
+func (ts templateSchedule) due(date time.Time) bool {
+	return ts.inBounds(date) && ts.adjusted(date)
+}
+
+// inBounds reports whether date falls within the From:, Until: and Count: limits.
+func (ts templateSchedule) inBounds(date time.Time) bool {
+	if !ts.from.IsZero() && daysBetween(ts.from, date) < 0 {
+		return false
+	}
+	return !ts.expired(date)
+}
+
+// end returns the last date the template can fire on, or the zero time if it
+// is unbounded or its Count: limit is too far off to look for.
+func (ts templateSchedule) end() time.Time {
+	end := ts.until
+	if countEnd := ts.countEnd(); !countEnd.IsZero() && (end.IsZero() || countEnd.Before(end)) {
+		end = countEnd
+	}
+	return end
+}
+
+// endBy returns the last date the template can fire on if it is known by
+// date: Until: or the count-th occurrence, if on or before date. Otherwise it
+// returns the zero time.
+func (ts templateSchedule) endBy(date time.Time) time.Time {
+	var end time.Time
+	if !ts.until.IsZero() && daysBetween(ts.until, date) >= 0 {
+		end = ts.until
+	}
+	if countEnd := ts.countEndBy(date); !countEnd.IsZero() && (end.IsZero() || countEnd.Before(end)) {
+		end = countEnd
+	}
+	return end
+}
+
+// expired reports whether the template can no longer fire on or after date.
+func (ts templateSchedule) expired(date time.Time) bool {
+	end := ts.endBy(date)
+	return !end.IsZero() && daysBetween(end, date) > 0
+}
+
+// adjusted reports whether the schedule lines put the template on date, ignoring
+// bounds and Except: lines. With an adjustment, a trigger that falls on a weekend
+// or holiday moves to the nearest business day in the adjustment's direction.
+func (ts templateSchedule) adjusted(date time.Time) bool {
+	switch ts.adjust {
+	case adjustNextBusinessDay:
+		if !ts.isBusinessDay(date) {
+			return false
+		}
+		if ts.scheduled(date) {
+			return true
+		}
+		for d := date.AddDate(0, 0, -1); !ts.isBusinessDay(d); d = d.AddDate(0, 0, -1) {
+			if ts.scheduled(d) {
+				return true
+			}
+		}
+		return false
+	case adjustPreviousBusinessDay:
+		if !ts.isBusinessDay(date) {
+			return false
+		}
+		if ts.scheduled(date) {
+			return true
+		}
+		for d := date.AddDate(0, 0, 1); !ts.isBusinessDay(d); d = d.AddDate(0, 0, 1) {
+			if ts.scheduled(d) {
+				return true
+			}
+		}
+		return false
+	default:
+		return ts.scheduled(date)
+	}
+}
+
+func templateMatchesSchedule(description string, date time.Time) bool {
+	return parseTemplateSchedule(description, nil).matches(date)
+}
+
+// civilDate returns t's calendar date as midnight UTC, the form schedules are matched against.
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// daysBetween returns the number of calendar days from one date to another.
+func daysBetween(from, to time.Time) int {
+	return int(civilDate(to).Sub(civilDate(from)).Hours() / 24)
+}
+
 func lastDayOfMonth(date time.Time) int {
 	nextMonth := time.Date(date.Year(), date.Month()+1, 1, 0, 0, 0, 0, time.UTC)
 	return nextMonth.AddDate(0, 0, -1).Day()
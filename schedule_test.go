@@ -103,3 +103,347 @@ func TestTemplateMatchesSchedule_AtInvalidDate(t *testing.T) {
 	desc := "At: not-a-date"
 	assert.False(t, templateMatchesSchedule(desc, date(2025, time.January, 15)))
 }
+
+func TestTemplateMatchesSchedule_EveryWeeks(t *testing.T) {
+	desc := "Recurrence: every 2 weeks from 2026-01-05"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.January, 5)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.January, 12)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.January, 19)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.March, 2)))
+	assert.False(t, templateMatchesSchedule(desc, date(2025, time.December, 22)))
+}
+
+func TestTemplateMatchesSchedule_EveryDays(t *testing.T) {
+	desc := "Recurrence: every 3 days from 2026-02-27"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.February, 27)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.March, 2)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.March, 3)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.February, 24)))
+}
+
+func TestTemplateMatchesSchedule_EveryMonths(t *testing.T) {
+	desc := "Recurrence: every 3 months from 2026-02-01"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.February, 1)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.March, 1)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.May, 1)))
+	assert.True(t, templateMatchesSchedule(desc, date(2027, time.February, 1)))
+	assert.False(t, templateMatchesSchedule(desc, date(2025, time.November, 1)))
+}
+
+func TestTemplateMatchesSchedule_EveryMonthClampsToLastDay(t *testing.T) {
+	desc := "Recurrence: every month from 2026-01-31"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.February, 28)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.April, 30)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.May, 31)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.May, 30)))
+}
+
+func TestParseRecurrence_EveryMalformed(t *testing.T) {
+	for _, value := range []string{
+		"every 0 weeks from 2026-01-05",
+		"every 2 fortnights from 2026-01-05",
+		"every 2 weeks since 2026-01-05",
+		"every 2 weeks from tomorrow",
+		"every 2 weeks",
+	} {
+		s := parseRecurrence(value, value)
+		assert.Equal(t, scheduleMalformed, s.kind, value)
+	}
+}
+
+func TestNextTriggerDates_Every(t *testing.T) {
+	ts := parseTemplateSchedule("Recurrence: every 2 weeks from 2026-01-05", nil)
+	dates := nextTriggerDates(ts, date(2026, time.January, 6), 365, 3)
+	assert.Equal(t, []time.Time{
+		date(2026, time.January, 19),
+		date(2026, time.February, 2),
+		date(2026, time.February, 16),
+	}, dates)
+}
+
+func TestTemplateMatchesSchedule_NthWeekday(t *testing.T) {
+	desc := "Recurrence: 2nd Tue"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.January, 13)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.September, 8)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.January, 6)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.January, 20)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.January, 14)))
+}
+
+func TestTemplateMatchesSchedule_FifthWeekday(t *testing.T) {
+	desc := "Recurrence: fifth Thu"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.January, 29)))
+	// February 2026 has only four Thursdays.
+	for d := 1; d <= 28; d++ {
+		assert.False(t, templateMatchesSchedule(desc, date(2026, time.February, d)))
+	}
+}
+
+func TestTemplateMatchesSchedule_LastWeekday(t *testing.T) {
+	desc := "Recurrence: last Fri"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.January, 30)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.February, 27)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.January, 23)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.January, 31)))
+}
+
+func TestTemplateMatchesSchedule_MonthNthWeekday(t *testing.T) {
+	desc := "Recurrence: first Mon of Jan"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.January, 5)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.February, 2)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.January, 12)))
+
+	desc = "Recurrence: last Sun of Mar"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.March, 29)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.March, 22)))
+}
+
+func TestParseRecurrence_NthWeekdayMalformed(t *testing.T) {
+	for _, value := range []string{
+		"2nd Tuesday",
+		"6th Tue",
+		"2nd Tue of Smarch",
+		"2nd Tue in Jan",
+	} {
+		s := parseRecurrence(value, value)
+		assert.Equal(t, scheduleMalformed, s.kind, value)
+	}
+}
+
+func TestTemplateMatchesSchedule_NextBusinessDay(t *testing.T) {
+	// 2026-08-01 is a Saturday.
+	desc := "Recurrence: 1|Adjust: next-business-day"
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.August, 1)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.August, 3)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.August, 4)))
+	// 2026-09-01 is a Tuesday and is not moved.
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.September, 1)))
+}
+
+func TestTemplateMatchesSchedule_PreviousBusinessDay(t *testing.T) {
+	// 2026-05-31 is a Sunday.
+	desc := "Recurrence: last|Adjust: previous-business-day"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.May, 29)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.May, 31)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.June, 30)))
+}
+
+func TestTemplateMatchesSchedule_AdjustSkipsHolidays(t *testing.T) {
+	// 2026-12-25 is a Friday, followed by a weekend and a Monday holiday.
+	desc := "Recurrence: Dec 25|Adjust: next-business-day|Holidays: 2026-12-25, 2026-12-28"
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.December, 25)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.December, 28)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.December, 29)))
+}
+
+func TestTemplateMatchesSchedule_AdjustMergesCollisions(t *testing.T) {
+	// Saturday and Sunday triggers both land on Monday; Monday fires once.
+	desc := "Recurrence: Sat|Recurrence: Sun|Adjust: next-business-day"
+	ts := parseTemplateSchedule(desc, nil)
+	dates := nextTriggerDates(ts, date(2026, time.January, 1), 14, 5)
+	assert.Equal(t, []time.Time{date(2026, time.January, 5), date(2026, time.January, 12)}, dates)
+}
+
+func TestParseTemplateSchedule_MalformedModifiers(t *testing.T) {
+	ts := parseTemplateSchedule("Recurrence: 1|Adjust: sometimes|Holidays: 2026-12-25, Xmas", nil)
+	assert.Equal(t, 3, len(ts.schedules))
+	assert.Equal(t, scheduleMalformed, ts.schedules[1].kind)
+	assert.Equal(t, scheduleMalformed, ts.schedules[2].kind)
+	assert.Equal(t, adjustNone, ts.adjust)
+	assert.Equal(t, 0, len(ts.holidays))
+}
+
+func TestTemplateMatchesSchedule_ExceptDates(t *testing.T) {
+	desc := "Recurrence: daily|Except: 2026-12-25, 2026-12-31"
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.December, 24)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.December, 25)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.December, 31)))
+}
+
+func TestTemplateMatchesSchedule_ExceptCalendar(t *testing.T) {
+	calendars := map[string]calendar{"company": {"2026-05-01": true}}
+	ts := parseTemplateSchedule("Recurrence: Fri|Except: company", calendars)
+	assert.True(t, ts.due(date(2026, time.May, 1)))
+	assert.True(t, ts.excepted(date(2026, time.May, 1)))
+	assert.False(t, ts.matches(date(2026, time.May, 1)))
+	assert.True(t, ts.matches(date(2026, time.May, 8)))
+}
+
+func TestTemplateMatchesSchedule_HolidaysCalendar(t *testing.T) {
+	calendars := map[string]calendar{"company": {"2026-06-01": true}}
+	// 2026-06-01 is a Monday.
+	ts := parseTemplateSchedule("Recurrence: 1|Adjust: next-business-day|Holidays: company", calendars)
+	assert.False(t, ts.matches(date(2026, time.June, 1)))
+	assert.True(t, ts.matches(date(2026, time.June, 2)))
+}
+
+func TestParseTemplateSchedule_ExceptUnknownCalendar(t *testing.T) {
+	ts := parseTemplateSchedule("Recurrence: daily|Except: nowhere", nil)
+	assert.Equal(t, 2, len(ts.schedules))
+	assert.Equal(t, scheduleMalformed, ts.schedules[1].kind)
+	assert.True(t, ts.matches(date(2026, time.January, 1)))
+}
+
+func TestTemplateMatchesSchedule_FromUntil(t *testing.T) {
+	desc := "Recurrence: Mon|From: 2026-03-01|Until: 2026-04-06"
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.February, 23)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.March, 2)))
+	assert.True(t, templateMatchesSchedule(desc, date(2026, time.April, 6)))
+	assert.False(t, templateMatchesSchedule(desc, date(2026, time.April, 13)))
+}
+
+func TestTemplateMatchesSchedule_Count(t *testing.T) {
+	desc := "Recurrence: Mon|Recurrence: Thu|From: 2026-03-01|Count: 3|Except: 2026-03-05"
+	ts := parseTemplateSchedule(desc, nil)
+	dates := nextTriggerDates(ts, date(2026, time.February, 1), 365, 10)
+	assert.Equal(t, []time.Time{
+		date(2026, time.March, 2),
+		date(2026, time.March, 5), // excepted, not counted
+		date(2026, time.March, 9),
+		date(2026, time.March, 12),
+	}, dates)
+	assert.Equal(t, date(2026, time.March, 12), ts.end())
+}
+
+func TestTemplateSchedule_Expired(t *testing.T) {
+	ts := parseTemplateSchedule("Recurrence: daily|Until: 2026-03-01", nil)
+	assert.False(t, ts.expired(date(2026, time.March, 1)))
+	assert.True(t, ts.expired(date(2026, time.March, 2)))
+
+	ts = parseTemplateSchedule("Recurrence: 1|From: 2026-01-01|Until: 2027-01-01|Count: 2", nil)
+	assert.Equal(t, date(2026, time.February, 1), ts.end())
+	assert.True(t, ts.expired(date(2026, time.February, 2)))
+
+	ts = parseTemplateSchedule("Recurrence: daily", nil)
+	assert.False(t, ts.expired(date(2100, time.January, 1)))
+
+	// Counting goes as far as the dates asked about, however far off.
+	ts = parseTemplateSchedule("At: 2040-01-01|At: 2050-06-01|At: 2051-01-01|From: 2026-03-01|Count: 2", nil)
+	assert.Equal(t, time.Time{}, ts.end(), "beyond the lookahead")
+	assert.True(t, ts.inBounds(date(2050, time.June, 1)))
+	assert.False(t, ts.expired(date(2050, time.June, 1)))
+	assert.True(t, ts.expired(date(2050, time.June, 2)))
+	assert.Equal(t, date(2050, time.June, 1), ts.endBy(date(2050, time.June, 2)), "known once reached")
+	assert.False(t, ts.due(date(2051, time.January, 1)))
+}
+
+func TestParseTemplateSchedule_CountWithoutFrom(t *testing.T) {
+	ts := parseTemplateSchedule("Count: 3|Recurrence: daily", nil)
+	assert.Equal(t, 0, ts.count)
+	assert.Equal(t, 2, len(ts.schedules))
+	assert.Equal(t, scheduleMalformed, ts.schedules[1].kind)
+	assert.Equal(t, "Count: 3", ts.schedules[1].raw)
+}
+
+func TestParseSchedules_DescriptionFormats(t *testing.T) {
+	monFri := []schedule{
+		{kind: scheduleWeekday, weekday: time.Monday},
+		{kind: scheduleWeekday, weekday: time.Friday},
+	}
+	tests := []struct {
+		name        string
+		description string
+	}{
+		{"pipes", "Recurrence: Mon|Recurrence: Fri"},
+		{"pipes with spaces", "Recurrence: Mon | Recurrence: Fri"},
+		{"newlines", "Recurrence: Mon\nRecurrence: Fri"},
+		{"paragraphs", "Weekly sync.\n\nRecurrence: Mon\n\nRecurrence: Fri\n"},
+		{"CRLF", "Recurrence: Mon\r\nRecurrence: Fri\r\n"},
+		{"hard breaks", "Recurrence: Mon\\\nRecurrence: Fri"},
+		{"trailing spaces", "Recurrence: Mon  \nRecurrence: Fri  "},
+		{"dash list", "- Recurrence: Mon\n- Recurrence: Fri"},
+		{"star list", "* Recurrence: Mon\n* Recurrence: Fri"},
+		{"numbered list", "1. Recurrence: Mon\n2. Recurrence: Fri"},
+		{"nested list", "- Schedule\n  - Recurrence: Mon\n  - Recurrence: Fri"},
+		{"task list", "- [ ] Recurrence: Mon\n- [x] Recurrence: Fri"},
+		{"blockquote", "> Recurrence: Mon\n> Recurrence: Fri"},
+		{"heading", "### Schedule\n\nRecurrence: Mon\nRecurrence: Fri"},
+		{"bold key", "**Recurrence:** Mon\n**Recurrence**: Fri"},
+		{"italic value", "Recurrence: *Mon*\nRecurrence: _Fri_"},
+		{"code", "`Recurrence: Mon`\nRecurrence: `Fri`"},
+		{"non-breaking space", "Recurrence:\u00a0Mon\nRecurrence:\u00a0Fri"},
+		{"mixed", "Intro\n\n- Recurrence: Mon | Recurrence: Fri\n\nOutro"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, monFri, parseSchedules(tt.description))
+		})
+	}
+}
+
+func TestParseSchedules_EscapedMarkdown(t *testing.T) {
+	schedules := parseSchedules("Recurrence: cron 0 0 \\*/2 \\* 1-5\nAt: 2026\\-03\\-01")
+	assert.Equal(t, 2, len(schedules))
+	assert.Equal(t, scheduleCron, schedules[0].kind)
+	assert.Equal(t, "0 0 */2 * 1-5", schedules[0].cron.expr)
+	assert.Equal(t, scheduleAt, schedules[1].kind)
+	assert.Equal(t, date(2026, time.March, 1), schedules[1].date)
+}
+
+func TestTemplateSchedule_Timezone(t *testing.T) {
+	ts := parseTemplateSchedule("Recurrence: Mon|Timezone: Australia/Sydney", nil)
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	assert.NoError(t, err)
+	assert.Equal(t, sydney, ts.location)
+
+	// Sunday 2026-03-01 22:00 UTC is Monday 09:00 in Sydney.
+	now := time.Date(2026, time.March, 1, 22, 0, 0, 0, time.UTC)
+	assert.Equal(t, date(2026, time.March, 2), ts.today(now, time.UTC))
+	assert.True(t, ts.matches(ts.today(now, time.UTC)))
+	assert.Equal(t, time.Date(2026, time.March, 2, 0, 0, 0, 0, sydney), ts.dayStart(now, time.UTC))
+
+	// Without a Timezone: line the default applies.
+	ts = parseTemplateSchedule("Recurrence: Mon", nil)
+	assert.Equal(t, date(2026, time.March, 1), ts.today(now, time.UTC))
+	assert.Equal(t, date(2026, time.March, 2), ts.today(now, sydney))
+}
+
+func TestTemplateSchedule_TimeOfDay(t *testing.T) {
+	ts := parseTemplateSchedule("Recurrence: daily|Time: 09:30|Timezone: Europe/Berlin", nil)
+	assert.Equal(t, 9*time.Hour+30*time.Minute, ts.timeOfDay)
+	// 2026-07-01 07:29 UTC is 09:29 in Berlin.
+	assert.False(t, ts.timeReached(time.Date(2026, time.July, 1, 7, 29, 0, 0, time.UTC), time.UTC))
+	assert.True(t, ts.timeReached(time.Date(2026, time.July, 1, 7, 30, 0, 0, time.UTC), time.UTC))
+
+	ts = parseTemplateSchedule("Recurrence: daily", nil)
+	assert.True(t, ts.timeReached(time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC), time.UTC))
+}
+
+func TestTemplateSchedule_TimeOfDayFromCron(t *testing.T) {
+	ts := parseTemplateSchedule("Recurrence: cron 15 8 * * 1-5", nil)
+	assert.Equal(t, 8*time.Hour+15*time.Minute, ts.timeOfDay)
+
+	ts = parseTemplateSchedule("Recurrence: cron 15 8 * * 1-5|Time: 10:00", nil)
+	assert.Equal(t, 10*time.Hour, ts.timeOfDay)
+
+	ts = parseTemplateSchedule("Recurrence: cron */30 8 * * 1-5", nil)
+	assert.Equal(t, time.Duration(0), ts.timeOfDay)
+}
+
+func TestParseTemplateSchedule_MalformedTimezoneAndTime(t *testing.T) {
+	ts := parseTemplateSchedule("Recurrence: daily|Timezone: Mars/Olympus_Mons|Time: 25:00", nil)
+	assert.Equal(t, 3, len(ts.schedules))
+	assert.Equal(t, scheduleMalformed, ts.schedules[1].kind)
+	assert.Equal(t, scheduleMalformed, ts.schedules[2].kind)
+	assert.Zero(t, ts.location)
+	assert.Equal(t, time.Duration(0), ts.timeOfDay)
+}
+
+func TestParseTemplateSchedule_CatchUp(t *testing.T) {
+	assert.Equal(t, catchUpLatest, parseTemplateSchedule("Recurrence: daily", nil).catchUp)
+	assert.Equal(t, catchUpAll, parseTemplateSchedule("Recurrence: daily|CatchUp: all", nil).catchUp)
+	assert.Equal(t, catchUpSkip, parseTemplateSchedule("Recurrence: daily|CatchUp: Skip", nil).catchUp)
+
+	ts := parseTemplateSchedule("Recurrence: daily|CatchUp: sometimes", nil)
+	assert.Equal(t, catchUpLatest, ts.catchUp)
+	assert.Equal(t, scheduleMalformed, ts.schedules[1].kind)
+}
+
+func TestTemplateSchedule_DateClock(t *testing.T) {
+	cfg := config{location: time.UTC, clock: dateClock(date(2028, time.February, 29))}
+	ts := parseTemplateSchedule("Recurrence: Feb last|Time: 23:00|Timezone: Pacific/Auckland", nil)
+	now := cfg.now(ts.locationOr(cfg.location))
+	assert.Equal(t, date(2028, time.February, 29), ts.today(now, cfg.location))
+	assert.True(t, ts.matches(ts.today(now, cfg.location)))
+	assert.True(t, ts.timeReached(now, cfg.location))
+}
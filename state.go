@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// runState is the catch-up state file: when each team last had a successful run.
+type runState struct {
+	LastRun map[string]time.Time `json:"lastRun"` // team ID -> start of the last successful run
+}
+
+// loadRunState reads the state file at path. A missing file is an empty state.
+func loadRunState(path string) (runState, error) {
+	state := runState{LastRun: map[string]time.Time{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, err
+	}
+	if state.LastRun == nil {
+		state.LastRun = map[string]time.Time{}
+	}
+	return state, nil
+}
+
+// save writes the state to path, replacing the old file only once the new one is complete.
+func (s runState) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
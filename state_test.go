@@ -0,0 +1,25 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestRunState_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	state, err := loadRunState(path)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(state.LastRun))
+
+	lastRun := time.Date(2026, time.October, 16, 6, 0, 0, 0, time.UTC)
+	state.LastRun["team-1"] = lastRun
+	assert.NoError(t, state.save(path))
+
+	loaded, err := loadRunState(path)
+	assert.NoError(t, err)
+	assert.True(t, lastRun.Equal(loaded.LastRun["team-1"]))
+}
@@ -1,19 +1,39 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 )
 
 type subIssueProblem struct {
-	title   string
-	problem string
+	title    string
+	problem  string
+	severity problemSeverity
+	check    string // short name of what is wrong, e.g. "dependency-cycle"
+}
+
+type problemSeverity int
+
+const (
+	severityError   problemSeverity = iota // setup would fail or do the wrong thing
+	severityWarning                        // setup works, but probably not as intended
+)
+
+func (s problemSeverity) String() string {
+	if s == severityWarning {
+		return "warning"
+	}
+	return "error"
 }
 
 // validateSubIssuePrefixes checks sub-issue titles for structural problems:
-// unparseable prefixes, duplicate IDs, and dangling DEPS references.
+// unparseable prefixes, duplicate IDs, dangling DEPS references and dependency
+// cycles. It also warns about sub-issues the parent does not end up waiting
+// for and dependencies that are implied by others.
 func validateSubIssuePrefixes(titles []string) []subIssueProblem {
 	var problems []subIssueProblem
 	ids := map[int]bool{}
@@ -26,7 +46,7 @@ func validateSubIssuePrefixes(titles []string) []subIssueProblem {
 	for _, title := range titles {
 		p, err := parseSubIssuePrefix(title)
 		if err != nil {
-			problems = append(problems, subIssueProblem{title: title, problem: err.Error()})
+			problems = append(problems, subIssueProblem{title: title, problem: err.Error(), check: "invalid-prefix"})
 			continue
 		}
 		items = append(items, parsed{title: title, prefix: p})
@@ -34,7 +54,7 @@ func validateSubIssuePrefixes(titles []string) []subIssueProblem {
 			continue
 		}
 		if ids[p.id] {
-			problems = append(problems, subIssueProblem{title: title, problem: fmt.Sprintf("duplicate ID %d", p.id)})
+			problems = append(problems, subIssueProblem{title: title, problem: fmt.Sprintf("duplicate ID %d", p.id), check: "duplicate-id"})
 		}
 		ids[p.id] = true
 	}
@@ -47,13 +67,151 @@ func validateSubIssuePrefixes(titles []string) []subIssueProblem {
 				problems = append(problems, subIssueProblem{
 					title:   item.title,
 					problem: fmt.Sprintf("sub-issue %d DEPS %d, but no sub-issue with that ID", item.prefix.id, need),
+					check:   "unknown-dependency",
 				})
 			}
 		}
 	}
+
+	// The dependency graph: each sub-issue points at the sub-issues it DEPS,
+	// and the parent at the REQ ones.
+	needs := map[int][]int{}
+	titleByID := map[int]string{}
+	var nodes []int
+	for _, item := range items {
+		if !item.prefix.hasPrefix || titleByID[item.prefix.id] != "" {
+			continue
+		}
+		nodes = append(nodes, item.prefix.id)
+		titleByID[item.prefix.id] = item.title
+		for _, need := range item.prefix.needs {
+			if ids[need] {
+				needs[item.prefix.id] = append(needs[item.prefix.id], need)
+			}
+		}
+		if item.prefix.req {
+			needs[parentNode] = append(needs[parentNode], item.prefix.id)
+		}
+	}
+
+	cycles := dependencyCycles(nodes, needs)
+	for _, cycle := range cycles {
+		path := make([]string, len(cycle))
+		for i, n := range cycle {
+			path[i] = strconv.Itoa(n)
+		}
+		problems = append(problems, subIssueProblem{
+			title:   titleByID[cycle[0]],
+			problem: fmt.Sprintf("dependency cycle %s: none of these sub-issues can ever be started", strings.Join(path, " -> ")),
+			check:   "dependency-cycle",
+		})
+	}
+	if len(cycles) > 0 {
+		// Reachability means little in a graph with cycles.
+		return problems
+	}
+
+	if len(needs[parentNode]) > 0 {
+		required := reachable(needs, parentNode)
+		for _, n := range nodes {
+			if !required[n] {
+				problems = append(problems, subIssueProblem{
+					title:    titleByID[n],
+					problem:  fmt.Sprintf("sub-issue %d is not REQ and no REQ sub-issue depends on it, so the parent does not wait for it", n),
+					severity: severityWarning,
+					check:    "not-required",
+				})
+			}
+		}
+	}
+
+	for _, n := range append([]int{parentNode}, nodes...) {
+		for _, need := range needs[n] {
+			if !impliedDependency(needs, n, need) {
+				continue
+			}
+			p := subIssueProblem{
+				title:    titleByID[n],
+				problem:  fmt.Sprintf("sub-issue %d DEPS %d is implied by its other dependencies", n, need),
+				severity: severityWarning,
+				check:    "implied-dependency",
+			}
+			if n == parentNode {
+				p.title = titleByID[need]
+				p.problem = fmt.Sprintf("sub-issue %d REQ is implied, as another REQ sub-issue depends on it", need)
+			}
+			problems = append(problems, p)
+		}
+	}
 	return problems
 }
 
+// parentNode stands for the parent issue in the dependency graph.
+const parentNode = -1
+
+// dependencyCycles returns the cycles found in the dependency graph, each as a
+// path that starts and ends at the same sub-issue.
+func dependencyCycles(nodes []int, needs map[int][]int) [][]int {
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := map[int]int{}
+	var path []int
+	var cycles [][]int
+	var visit func(n int)
+	visit = func(n int) {
+		state[n] = onPath
+		path = append(path, n)
+		for _, need := range needs[n] {
+			switch state[need] {
+			case unvisited:
+				visit(need)
+			case onPath:
+				start := slices.Index(path, need)
+				cycles = append(cycles, append(slices.Clone(path[start:]), need))
+			}
+		}
+		path = path[:len(path)-1]
+		state[n] = done
+	}
+	for _, n := range nodes {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+	return cycles
+}
+
+// reachable returns the nodes from reaches through the graph, not including
+// from itself.
+func reachable(needs map[int][]int, from int) map[int]bool {
+	seen := map[int]bool{}
+	var visit func(n int)
+	visit = func(n int) {
+		for _, need := range needs[n] {
+			if !seen[need] {
+				seen[need] = true
+				visit(need)
+			}
+		}
+	}
+	visit(from)
+	return seen
+}
+
+// impliedDependency reports whether n's dependency on need is implied by n's
+// other dependencies, that is, whether one of them depends on need too.
+func impliedDependency(needs map[int][]int, n, need int) bool {
+	for _, other := range needs[n] {
+		if other != need && reachable(needs, other)[need] {
+			return true
+		}
+	}
+	return false
+}
+
 // subIssuePrefix represents the parsed prefix from a sub-issue title.
 type subIssuePrefix struct {
 	id       int    // numeric ID of this sub-issue (0 if no prefix)
@@ -105,8 +263,12 @@ func parseSubIssuePrefix(title string) (subIssuePrefix, error) {
 // setupSubIssueDependencies parses sub-issue title prefixes, creates dependency
 // relations, and strips prefixes from titles. Call after creating an issue from
 // a template.
-func setupSubIssueDependencies(q q, parentID string) error {
-	children, err := getChildIssues(q, parentID)
+//
+// The original titles are kept in a comment on the parent, so that setup can be
+// repeated after the prefixes are gone. Relations that already exist are left
+// alone, which makes it safe to run again on a partly set-up issue.
+func setupSubIssueDependencies(ctx context.Context, q q, parentID string) error {
+	children, err := getChildIssues(ctx, q, parentID)
 	if err != nil {
 		return fmt.Errorf("fetching sub-issues: %w", err)
 	}
@@ -118,6 +280,22 @@ func setupSubIssueDependencies(q q, parentID string) error {
 		return nil
 	}
 
+	comments, err := getIssueComments(ctx, q, parentID)
+	if err != nil {
+		return fmt.Errorf("fetching comments: %w", err)
+	}
+	originalTitles := parsePrefixComments(comments)
+
+	existing := map[[2]string]bool{} // blocker, blocked
+	for _, child := range children {
+		for _, blocked := range child.blocks {
+			existing[[2]string{child.id, blocked}] = true
+		}
+		for _, blocker := range child.blockedBy {
+			existing[[2]string{blocker, child.id}] = true
+		}
+	}
+
 	// Parse all prefixes and build a map from numeric ID to Linear issue ID.
 	type parsed struct {
 		sub    subIssue
@@ -125,16 +303,59 @@ func setupSubIssueDependencies(q q, parentID string) error {
 	}
 	var items []parsed
 	idMap := map[int]string{} // numeric prefix ID -> Linear issue ID
+	var unrecorded []subIssue // prefixed sub-issues missing from the comments
 
-	for _, child := range children {
-		p, err := parseSubIssuePrefix(child.title)
+	titles := make([]string, len(children))
+	for i, child := range children {
+		titles[i] = child.title
+		if original, ok := originalTitles[child.id]; ok {
+			titles[i] = original
+		}
+	}
+	// Refuse to create a graph that cannot be worked through.
+	var invalid []string
+	for _, p := range validateSubIssuePrefixes(titles) {
+		if p.severity == severityError {
+			invalid = append(invalid, fmt.Sprintf("%q: %s", p.title, p.problem))
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("invalid sub-issue prefixes: %s", strings.Join(invalid, "; "))
+	}
+
+	for i, child := range children {
+		title := titles[i]
+		_, recorded := originalTitles[child.id]
+		p, err := parseSubIssuePrefix(title)
 		if err != nil {
-			return fmt.Errorf("parsing sub-issue %q: %w", child.title, err)
+			return fmt.Errorf("parsing sub-issue %q: %w", title, err)
 		}
 		items = append(items, parsed{sub: child, prefix: p})
 		if p.hasPrefix && p.id > 0 {
 			idMap[p.id] = child.id
 		}
+		if p.hasPrefix && !recorded {
+			unrecorded = append(unrecorded, child)
+		}
+	}
+
+	// Record the prefixes before they are stripped from the titles.
+	if len(unrecorded) > 0 {
+		if err := createComment(ctx, q, parentID, formatPrefixComment(unrecorded)); err != nil {
+			return fmt.Errorf("recording original sub-issue titles: %w", err)
+		}
+	}
+
+	link := func(blockerID, blockedID string) error {
+		if existing[[2]string{blockerID, blockedID}] {
+			fmt.Println("    already set up")
+			return nil
+		}
+		if err := createBlocksRelation(ctx, q, blockerID, blockedID); err != nil {
+			return err
+		}
+		existing[[2]string{blockerID, blockedID}] = true
+		return nil
 	}
 
 	// Create relations and strip prefixes.
@@ -146,7 +367,7 @@ func setupSubIssueDependencies(q q, parentID string) error {
 		// REQ: parent depends on this sub-issue (this sub-issue blocks parent).
 		if item.prefix.req {
 			fmt.Printf("  sub-issue %d blocks parent\n", item.prefix.id)
-			if err := createBlocksRelation(q, item.sub.id, parentID); err != nil {
+			if err := link(item.sub.id, parentID); err != nil {
 				return fmt.Errorf("creating REQ relation for sub-issue %d: %w", item.prefix.id, err)
 			}
 		}
@@ -158,7 +379,7 @@ func setupSubIssueDependencies(q q, parentID string) error {
 				return fmt.Errorf("sub-issue %d DEPS %d, but no sub-issue with that ID found", item.prefix.id, needID)
 			}
 			fmt.Printf("  sub-issue %d depends on sub-issue %d\n", item.prefix.id, needID)
-			if err := createBlocksRelation(q, blockerLinearID, item.sub.id); err != nil {
+			if err := link(blockerLinearID, item.sub.id); err != nil {
 				return fmt.Errorf("creating DEPS relation for sub-issue %d -> %d: %w", item.prefix.id, needID, err)
 			}
 		}
@@ -166,7 +387,7 @@ func setupSubIssueDependencies(q q, parentID string) error {
 		// Strip the prefix from the title.
 		if item.prefix.title != item.sub.title {
 			fmt.Printf("  renaming sub-issue %q -> %q\n", item.sub.title, item.prefix.title)
-			if err := updateTitle(q, item.sub.id, item.prefix.title); err != nil {
+			if err := updateTitle(ctx, q, item.sub.id, item.prefix.title); err != nil {
 				return fmt.Errorf("stripping prefix from sub-issue %d: %w", item.prefix.id, err)
 			}
 		}
@@ -174,3 +395,35 @@ func setupSubIssueDependencies(q q, parentID string) error {
 
 	return nil
 }
+
+// prefixCommentHeader starts the parent issue comment that keeps the original
+// titles of its sub-issues. It is followed by a code block with one sub-issue
+// ID and title per line.
+const prefixCommentHeader = "Sub-issue dependencies are set up by linear-future from these original titles:"
+
+func formatPrefixComment(children []subIssue) string {
+	var b strings.Builder
+	b.WriteString(prefixCommentHeader + "\n\n```\n")
+	for _, child := range children {
+		fmt.Fprintf(&b, "%s %s\n", child.id, child.title)
+	}
+	b.WriteString("```")
+	return b.String()
+}
+
+// parsePrefixComments returns the original sub-issue titles recorded in an
+// issue's comments, by sub-issue ID.
+func parsePrefixComments(comments []string) map[string]string {
+	titles := map[string]string{}
+	for _, body := range comments {
+		if !strings.HasPrefix(body, prefixCommentHeader) {
+			continue
+		}
+		for _, line := range strings.Split(body, "\n")[1:] {
+			if id, title, ok := strings.Cut(strings.TrimSpace(line), " "); ok {
+				titles[id] = title
+			}
+		}
+	}
+	return titles
+}
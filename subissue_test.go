@@ -1,7 +1,10 @@
 package main
 
 import (
+	"context"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/alecthomas/assert/v2"
 )
@@ -95,3 +98,122 @@ func TestParseSubIssuePrefix_SelfDependency(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "depends on itself")
 }
+
+func TestSetupSubIssueDependencies_Idempotent(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	ctx := context.Background()
+	fake.addTemplate("team-1", "T", "", "1|REQ Prepare", "2|DEPS1 Run", "Notes")
+	parentID, err := createIssueFromTemplate(ctx, q, "template-1", "team-1", time.Time{})
+	assert.NoError(t, err)
+
+	assert.NoError(t, setupSubIssueDependencies(ctx, q, parentID))
+	assert.Equal(t, 2, len(fake.relations))
+	assert.Equal(t, 1, len(fake.issue(parentID).comments))
+
+	// Running again changes nothing.
+	assert.NoError(t, setupSubIssueDependencies(ctx, q, parentID))
+	assert.Equal(t, 2, len(fake.relations))
+	assert.Equal(t, 1, len(fake.issue(parentID).comments))
+
+	// A relation lost after the titles were stripped is restored from the
+	// original titles kept in the comment.
+	fake.relations = fake.relations[:1]
+	assert.NoError(t, setupSubIssueDependencies(ctx, q, parentID))
+	assert.Equal(t, 2, len(fake.relations))
+	children, err := getChildIssues(ctx, q, parentID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Prepare", "Run", "Notes"}, []string{children[0].title, children[1].title, children[2].title})
+}
+
+func TestSetupSubIssueDependencies_PaginatesRelations(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	fake.pageSize = 2
+	ctx := context.Background()
+	fake.addTemplate("team-1", "T", "", "1|REQ Prepare", "2|DEPS1 A", "3|DEPS1 B", "4|DEPS1 C")
+	parentID, err := createIssueFromTemplate(ctx, q, "template-1", "team-1", time.Time{})
+	assert.NoError(t, err)
+	assert.NoError(t, setupSubIssueDependencies(ctx, q, parentID))
+	assert.Equal(t, 4, len(fake.relations))
+
+	// Prepare blocks four issues, two pages' worth; none is added again.
+	children, err := getChildIssues(ctx, q, parentID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{parentID, children[1].id, children[2].id, children[3].id}, children[0].blocks)
+	assert.Equal(t, []string{children[0].id}, children[3].blockedBy)
+	assert.NoError(t, setupSubIssueDependencies(ctx, q, parentID))
+	assert.Equal(t, 4, len(fake.relations))
+}
+
+func TestSetupSubIssueDependencies_ResumesPartialSetup(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	ctx := context.Background()
+	fake.addTemplate("team-1", "T", "", "1|REQ Prepare", "2|DEPS1 Run")
+	parentID, err := createIssueFromTemplate(ctx, q, "template-1", "team-1", time.Time{})
+	assert.NoError(t, err)
+
+	// The first relation is created before the second one fails.
+	fake.onRequest = func(field string) {
+		if field == "issueRelationCreate" && len(fake.relations) == 1 {
+			fake.failures = []fakeFailure{{status: http.StatusBadRequest, code: "INVALID_INPUT"}}
+		}
+	}
+	assert.Error(t, setupSubIssueDependencies(ctx, q, parentID))
+	assert.Equal(t, 1, len(fake.relations))
+
+	fake.onRequest = nil
+	assert.NoError(t, setupSubIssueDependencies(ctx, q, parentID))
+	assert.Equal(t, 2, len(fake.relations))
+	assert.Equal(t, 1, len(fake.issue(parentID).comments))
+}
+
+func TestParsePrefixComments(t *testing.T) {
+	comment := formatPrefixComment([]subIssue{{id: "issue-2", title: "1|REQ Prepare"}, {id: "issue-3", title: "2|DEPS1 Run it"}})
+	titles := parsePrefixComments([]string{"Unrelated comment\nissue-2 Something", comment})
+	assert.Equal(t, map[string]string{"issue-2": "1|REQ Prepare", "issue-3": "2|DEPS1 Run it"}, titles)
+}
+
+func TestValidateSubIssuePrefixes_Cycle(t *testing.T) {
+	problems := validateSubIssuePrefixes([]string{"1|REQ|DEPS2 A", "2|DEPS3 B", "3|DEPS1 C", "4 D"})
+	assert.Equal(t, []subIssueProblem{
+		{title: "1|REQ|DEPS2 A", problem: "dependency cycle 1 -> 2 -> 3 -> 1: none of these sub-issues can ever be started", check: "dependency-cycle"},
+	}, problems)
+}
+
+func TestValidateSubIssuePrefixes_Unrequired(t *testing.T) {
+	problems := validateSubIssuePrefixes([]string{"1|REQ|DEPS2 A", "2 B", "3 C", "Plain"})
+	assert.Equal(t, []subIssueProblem{{
+		title:    "3 C",
+		problem:  "sub-issue 3 is not REQ and no REQ sub-issue depends on it, so the parent does not wait for it",
+		severity: severityWarning,
+		check:    "not-required",
+	}}, problems)
+
+	// Without any REQ, nothing is expected to be required.
+	assert.Equal(t, 0, len(validateSubIssuePrefixes([]string{"1 A", "2|DEPS1 B"})))
+}
+
+func TestValidateSubIssuePrefixes_Implied(t *testing.T) {
+	problems := validateSubIssuePrefixes([]string{"1|REQ A", "2|REQ|DEPS1 B", "3|REQ|DEPS1|DEPS2 C"})
+	assert.Equal(t, []subIssueProblem{
+		{title: "1|REQ A", problem: "sub-issue 1 REQ is implied, as another REQ sub-issue depends on it", severity: severityWarning, check: "implied-dependency"},
+		{title: "2|REQ|DEPS1 B", problem: "sub-issue 2 REQ is implied, as another REQ sub-issue depends on it", severity: severityWarning, check: "implied-dependency"},
+		{title: "3|REQ|DEPS1|DEPS2 C", problem: "sub-issue 3 DEPS 1 is implied by its other dependencies", severity: severityWarning, check: "implied-dependency"},
+	}, problems)
+}
+
+func TestSetupSubIssueDependencies_RefusesCycle(t *testing.T) {
+	fake, q := newFakeLinear(t)
+	ctx := context.Background()
+	fake.addTemplate("team-1", "T", "", "1|REQ|DEPS2 A", "2|DEPS1 B")
+	parentID, err := createIssueFromTemplate(ctx, q, "template-1", "team-1", time.Time{})
+	assert.NoError(t, err)
+
+	err = setupSubIssueDependencies(ctx, q, parentID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "dependency cycle 1 -> 2 -> 1")
+	assert.Equal(t, 0, len(fake.relations))
+	assert.Equal(t, 0, len(fake.issue(parentID).comments))
+	children, err := getChildIssues(ctx, q, parentID)
+	assert.NoError(t, err)
+	assert.Equal(t, "1|REQ|DEPS2 A", children[0].title)
+}
@@ -1,17 +1,35 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 )
 
-func runListTemplates(token string) int {
-	q := q{token}
-	templates, err := getTemplates(q)
+func runListTemplates(ctx context.Context, token string, cfg config) int {
+	q := cfg.newQ(token)
+	templates, err := getTemplates(ctx, q)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to list templates: %v\n", err)
 		return 1
 	}
+	if cfg.format == "json" || cfg.format == "yaml" {
+		type templateSummary struct {
+			ID          string `json:"id"`
+			TeamID      string `json:"teamId"`
+			Name        string `json:"name"`
+			Description string `json:"description"`
+		}
+		summaries := make([]templateSummary, 0, len(templates))
+		for _, t := range templates {
+			summaries = append(summaries, templateSummary{ID: t.id, TeamID: t.teamID, Name: t.name, Description: t.description})
+		}
+		if err := writeFormatted(os.Stdout, cfg.format, summaries); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list templates: %v\n", err)
+			return 1
+		}
+		return 0
+	}
 	for _, t := range templates {
 		fmt.Printf("%s\t%s\t%s\t%s\n", t.id, t.teamID, t.name, t.description)
 	}